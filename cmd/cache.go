@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mullerhx/gover/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cleanPartialOnly bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the downloaded archive cache",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached archives",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := newInstaller().Cache.Entries()
+		if err != nil {
+			fmt.Println("Failed to read cache:", err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			fmt.Println("Cache is empty.")
+			return
+		}
+		for _, e := range entries {
+			status := ""
+			if e.Partial {
+				status = " (partial)"
+			}
+			fmt.Printf("%-36s %10s  %s%s\n", e.Filename, formatBytes(e.Size), e.Sha256[:min(12, len(e.Sha256))], status)
+		}
+	},
+}
+
+var cacheSizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Show the disk space used by the cache",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := newInstaller().Cache
+		size, err := c.Size()
+		if err != nil {
+			fmt.Println("Failed to read cache:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s\t%s\n", formatBytes(size), c.Dir)
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove cached archives",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var keep func(cache.Entry) bool
+		if cleanPartialOnly {
+			keep = func(e cache.Entry) bool { return !e.Partial }
+		}
+		freed, err := newInstaller().Cache.Clean(keep)
+		if err != nil {
+			fmt.Println("Failed to clean cache:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Freed %s\n", formatBytes(freed))
+	},
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	cacheCleanCmd.Flags().BoolVar(&cleanPartialOnly, "partial", false, "Only remove incomplete downloads")
+	cacheCmd.AddCommand(cacheListCmd, cacheSizeCmd, cacheCleanCmd)
+	RootCmd.AddCommand(cacheCmd)
+}
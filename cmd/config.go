@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadConfigOnce sync.Once
+	loadedConfig   *config.Config
+)
+
+// settings returns the configuration file contents. A file that cannot be
+// read is reported once and otherwise ignored so that gover keeps working
+// with its defaults.
+func settings() *config.Config {
+	loadConfigOnce.Do(func() {
+		loadedConfig = &config.Config{}
+		path, err := config.Path()
+		if err != nil {
+			return
+		}
+		c, err := config.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gover: ignoring configuration:", err)
+			return
+		}
+		loadedConfig = c
+	})
+	return loadedConfig
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change gover settings",
+	Long: `Read and change gover settings.
+
+Settings are stored in $GOVER_CONFIG, or else in
+$XDG_CONFIG_HOME/gover/config.toml (~/.config/gover/config.toml by default).
+Environment variables and flags take precedence over the file.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setting := lookupSetting(args[0])
+		value := setting.Get(settings())
+		if value == "" {
+			value = setting.Default
+		}
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting; an empty value restores the default",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		setting := lookupSetting(args[0])
+		path, err := config.Path()
+		if err != nil {
+			fmt.Println("Failed to locate configuration file:", err)
+			os.Exit(1)
+		}
+		// Load the file again rather than using settings() so that a
+		// broken file is reported instead of being overwritten.
+		c, err := config.Load(path)
+		if err != nil {
+			fmt.Println("Failed to load configuration:", err)
+			os.Exit(1)
+		}
+		if err := setting.Set(c, args[1]); err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		if err := c.Save(path); err != nil {
+			fmt.Println("Failed to save configuration:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s = %s\n", setting.Key, displayValue(setting, c))
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings and their values",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := settings()
+		width := 0
+		for _, s := range config.Settings {
+			width = max(width, len(s.Key))
+		}
+		for _, s := range config.Settings {
+			fmt.Printf("%-*s = %s\n", width, s.Key, displayValue(s, c))
+		}
+		if path, err := config.Path(); err == nil {
+			fmt.Println()
+			fmt.Println("👉 Stored in", path)
+		}
+	},
+}
+
+func lookupSetting(key string) config.Setting {
+	setting, ok := config.Lookup(key)
+	if !ok {
+		var keys []string
+		for _, s := range config.Settings {
+			keys = append(keys, s.Key)
+		}
+		fmt.Printf("❌ Unknown setting %q. Known settings: %s\n", key, strings.Join(keys, ", "))
+		os.Exit(1)
+	}
+	return setting
+}
+
+func displayValue(s config.Setting, c *config.Config) string {
+	if value := s.Get(c); value != "" {
+		return value
+	}
+	return s.Default + " (default)"
+}
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	RootCmd.AddCommand(configCmd)
+}
@@ -1,46 +1,58 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"os/user"
-	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// currentInfo is the selection printed by `gover current --output json`.
+// Source is "env", "file" or "global"; File names the version file or
+// go.mod when Source is "file".
+type currentInfo struct {
+	Version string `json:"version"`
+	GOROOT  string `json:"goroot"`
+	Source  string `json:"source"`
+	File    string `json:"file,omitempty"`
+}
+
 var currentCmd = &cobra.Command{
 	Use:   "current",
 	Short: "Show the currently active Go version",
-	Run: func(cmd *cobra.Command, args []string) {
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Println("Failed to get user home:", err)
-			os.Exit(1)
-		}
+	Long: `Show the Go version active in the current directory.
 
-		symlink := filepath.Join(usr.HomeDir, ".gover", "current")
-
-		target, err := os.Readlink(symlink)
+The version comes from GOVER_VERSION, the nearest .go-version, .tool-versions,
+go.work or go.mod, or else the global default set by ` + "`gover use`" + `.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		version, source, err := selectVersion()
 		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Println("No Go version currently active. Use `gover use <version>`.")
-			} else {
-				fmt.Println("Failed to read symlink:", err)
+			if errors.Is(err, errNoVersion) {
+				failf("No Go version currently active. Use `gover use <version>`.")
 			}
-			os.Exit(1)
+			failf("Failed to select version: %v", err)
 		}
 
-		// Extract version from the symlink target
-		versionsDir := filepath.Join(usr.HomeDir, ".gover", "versions") + string(os.PathSeparator)
-		version := target
-		if strings.HasPrefix(target, versionsDir) {
-			version = target[len(versionsDir):]
+		info := currentInfo{Version: version, GOROOT: newInstaller().Dir(version), Source: "file"}
+		switch source {
+		case "global":
+			info.Source = "global"
+			info.GOROOT = newInstaller().CurrentLink()
+		case versionEnv:
+			info.Source = "env"
+		default:
+			info.File = source
 		}
 
-		fmt.Println("Current Go version:", version)
-		fmt.Println("GOROOT:", symlink)
+		if jsonOutput() {
+			printJSON(info)
+			return
+		}
+		fmt.Println("Current Go version:", info.Version)
+		fmt.Println("GOROOT:", info.GOROOT)
+		if info.Source != "global" {
+			fmt.Println("Set by:", source)
+		}
 	},
 }
 
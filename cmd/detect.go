@@ -2,23 +2,64 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/spf13/cobra"
 	"os"
+
+	"github.com/spf13/cobra"
 )
 
+// detectInfo is printed by `gover detect --output json`. Requested is the
+// version or constraint as written in File, and Directive the go.mod or
+// go.work directive it came from. Pinned reports whether it names an exact
+// version rather than a minimum.
+type detectInfo struct {
+	Version   string `json:"version"`
+	Requested string `json:"requested"`
+	File      string `json:"file"`
+	Directive string `json:"directive,omitempty"`
+	Pinned    bool   `json:"pinned"`
+}
+
 var detectCmd = &cobra.Command{
 	Use:   "detect",
-	Short: "Detect Go version from nearest go.mod and resolve latest patch version",
+	Short: "Detect the Go version the current directory asks for",
+	Long: `Detect the Go version the current directory asks for.
+
+The nearest .go-version or .tool-versions file found by walking up from the
+current directory pins the version; one in the home directory only counts
+outside modules. Without one, the workspace's go.work or else the nearest
+go.mod decides: its toolchain directive pins the version, and otherwise its
+go directive is resolved according to --resolution or the resolution
+setting:
+
+  exact             the minimum itself, e.g. go1.21.0 for "go 1.21"
+  latest-patch      the newest release of that line (default)
+  latest-installed  the newest installed version from the minimum on
+  latest-minor      the newest release from the minimum on
+
+The version is printed alone on standard output, and the file it came from
+on standard error.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		version, err := detectGoModVersion()
+		version, req, err := detectVersion()
 		if err != nil {
-			fmt.Println("❌", err)
-			os.Exit(1)
+			failf("❌ %v", err)
+		}
+		if jsonOutput() {
+			printJSON(detectInfo{
+				Version:   version,
+				Requested: req.Version,
+				File:      req.File,
+				Directive: req.Directive,
+				Pinned:    req.Exact,
+			})
+			return
 		}
+		// Keep stdout to the bare version so that scripts can capture it.
+		fmt.Fprintf(os.Stderr, "Detected %s from %s\n", version, requestSource(req))
 		fmt.Println(version)
 	},
 }
 
 func init() {
+	addResolutionFlag(detectCmd)
 	RootCmd.AddCommand(detectCmd)
 }
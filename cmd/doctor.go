@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mullerhx/gover/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+// checkResult is the outcome of a single doctor check. When repair is set
+// the problem can be fixed safely with --fix.
+type checkResult struct {
+	status  checkStatus
+	message string
+	hint    string
+	repair  func() error
+}
+
+type doctorCheck struct {
+	name string
+	run  func() checkResult
+}
+
+var doctorChecks = []doctorCheck{
+	{"release index", checkReleaseIndex},
+	{"install layout", checkInstallLayout},
+	{"installed versions", checkInstalledVersions},
+	{"current version", checkCurrentLink},
+	{"interrupted installs", checkStaging},
+	{"PATH", checkPath},
+	{"GOROOT", checkGoroot},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the gover setup",
+	Long: `Diagnose problems with the gover setup.
+
+Each check reports pass, warn or fail together with a suggestion. With
+--fix, problems that can be repaired without side effects are fixed:
+fetching a missing release index, removing a dangling current link,
+migrating old install layouts and deleting incomplete installs.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, check := range doctorChecks {
+			result := check.run()
+			if result.status != checkPass && doctorFix && result.repair != nil {
+				if err := result.repair(); err != nil {
+					result.hint = fmt.Sprintf("automatic fix failed: %v", err)
+				} else {
+					result = checkResult{status: checkPass, message: "fixed: " + result.message}
+				}
+			}
+
+			switch result.status {
+			case checkPass:
+				fmt.Printf("✅ %s: %s\n", check.name, result.message)
+			case checkWarn:
+				fmt.Printf("⚠️  %s: %s\n", check.name, result.message)
+			case checkFail:
+				fmt.Printf("❌ %s: %s\n", check.name, result.message)
+				failed = true
+			}
+			if result.status != checkPass {
+				if result.hint != "" {
+					fmt.Println("   👉", result.hint)
+				}
+				if result.repair != nil && !doctorFix {
+					fmt.Println("   👉 run `gover doctor --fix` to repair")
+				}
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func checkReleaseIndex() checkResult {
+	path := resolvePaths().Index
+	fetch := func() error {
+		_, err := loadReleases(true)
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkResult{
+			status:  checkFail,
+			message: path + " is missing, so upgrade, detect and constraints cannot resolve versions",
+			hint:    "run `gover init`",
+			repair:  fetch,
+		}
+	}
+	if err != nil {
+		return checkResult{status: checkFail, message: err.Error()}
+	}
+
+	var versions []GoVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return checkResult{
+			status:  checkFail,
+			message: path + " is corrupt: " + err.Error(),
+			hint:    "run `gover list --force`",
+			repair:  fetch,
+		}
+	}
+	age := time.Since(readIndexMeta(path).FetchedAt)
+	message := fmt.Sprintf("%d releases cached, fetched %s ago", len(versions), formatAge(age))
+	if age > indexTTL() {
+		message += "; it is refreshed on next use"
+	}
+	return checkResult{status: checkPass, message: message}
+}
+
+func checkCurrentLink() checkResult {
+	inst := newInstaller()
+	link := inst.CurrentLink()
+	target, err := os.Readlink(link)
+	if os.IsNotExist(err) {
+		return checkResult{status: checkWarn, message: "no version selected", hint: "run `gover use <version>`"}
+	}
+	if err != nil {
+		return checkResult{
+			status:  checkFail,
+			message: link + " is not a symlink",
+			hint:    "remove it and run `gover use <version>`",
+		}
+	}
+
+	name := strings.TrimPrefix(target, inst.VersionsDir()+string(os.PathSeparator))
+	if !fileExists(filepath.Join(target, "bin", "go")) {
+		return checkResult{
+			status:  checkFail,
+			message: fmt.Sprintf("current points to %s, which is not installed", target),
+			hint:    "run `gover use <version>`",
+			repair:  func() error { return os.Remove(link) },
+		}
+	}
+	return checkResult{status: checkPass, message: name}
+}
+
+// versionDirs lists the version directories, skipping staging leftovers.
+func versionDirs() ([]string, error) {
+	entries, err := os.ReadDir(newInstaller().VersionsDir())
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func checkInstallLayout() checkResult {
+	inst := newInstaller()
+	names, _ := versionDirs()
+	var legacy []string
+	for _, name := range names {
+		dir := inst.Dir(name)
+		if fileExists(filepath.Join(dir, "go", "bin")) && !fileExists(filepath.Join(dir, "bin")) {
+			legacy = append(legacy, name)
+		}
+	}
+	if len(legacy) == 0 {
+		return checkResult{status: checkPass, message: "all versions use the current layout"}
+	}
+	return checkResult{
+		status:  checkWarn,
+		message: "installs in the old versions/<version>/go layout: " + strings.Join(legacy, ", "),
+		repair: func() error {
+			for _, name := range legacy {
+				if err := inst.Migrate(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func checkInstalledVersions() checkResult {
+	inst := newInstaller()
+	names, err := versionDirs()
+	if os.IsNotExist(err) || (err == nil && len(names) == 0) {
+		return checkResult{status: checkWarn, message: "no versions installed", hint: "run `gover install stable`"}
+	}
+	if err != nil {
+		return checkResult{status: checkFail, message: err.Error()}
+	}
+
+	var broken []string
+	for _, name := range names {
+		dir := inst.Dir(name)
+		if !fileExists(filepath.Join(dir, "bin", "go")) && !fileExists(filepath.Join(dir, "go", "bin", "go")) {
+			broken = append(broken, name)
+		}
+	}
+	if len(broken) == 0 {
+		return checkResult{status: checkPass, message: fmt.Sprintf("%d installed", len(names))}
+	}
+	return checkResult{
+		status:  checkFail,
+		message: "incomplete installs without bin/go: " + strings.Join(broken, ", "),
+		hint:    "reinstall them with `gover install <version>`",
+		repair: func() error {
+			for _, name := range broken {
+				if err := os.RemoveAll(inst.Dir(name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func checkStaging() checkResult {
+	inst := newInstaller()
+	entries, _ := os.ReadDir(inst.VersionsDir())
+	var leftovers []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".staging-") {
+			leftovers = append(leftovers, e.Name())
+		}
+	}
+	if len(leftovers) == 0 {
+		return checkResult{status: checkPass, message: "none"}
+	}
+	return checkResult{
+		status:  checkWarn,
+		message: "staging directories left behind: " + strings.Join(leftovers, ", "),
+		repair: func() error {
+			inst.CleanStaging()
+			return nil
+		},
+	}
+}
+
+// checkPath verifies that the go found on PATH is one gover manages.
+func checkPath() checkResult {
+	paths := resolvePaths()
+	currentBin := filepath.Join(paths.Current, "bin")
+	found, err := exec.LookPath("go")
+	if err != nil {
+		return checkResult{
+			status:  checkWarn,
+			message: "no go binary on PATH",
+			hint:    fmt.Sprintf("add %s (or %s) to PATH, or run `gover init %s`", currentBin, shimsDir(), detectShell()),
+		}
+	}
+
+	abs, _ := filepath.Abs(found)
+	if !paths.managed(abs) {
+		return checkResult{
+			status:  checkWarn,
+			message: fmt.Sprintf("%s comes before gover's Go on PATH", abs),
+			hint:    fmt.Sprintf("put %s before %s in PATH", currentBin, filepath.Dir(abs)),
+		}
+	}
+	return checkResult{status: checkPass, message: abs}
+}
+
+// checkGoroot verifies that GOROOT, when set, points into gover.
+func checkGoroot() checkResult {
+	goroot := os.Getenv("GOROOT")
+	if goroot == "" {
+		return checkResult{status: checkPass, message: "not set; the go binary finds its own"}
+	}
+
+	paths := resolvePaths()
+	if !paths.managed(goroot) {
+		return checkResult{
+			status:  checkWarn,
+			message: fmt.Sprintf("GOROOT=%s is not managed by gover", goroot),
+			hint:    fmt.Sprintf("unset GOROOT or set it to %s", paths.Current),
+		}
+	}
+
+	if !fileExists(filepath.Join(goroot, "bin", "go")) {
+		hint := "run `gover use <version>` in this shell"
+		if name := filepath.Base(goroot); isVersionName(name) {
+			hint = "run `gover install " + name + "`"
+		}
+		return checkResult{
+			status:  checkFail,
+			message: fmt.Sprintf("GOROOT=%s has no Go installed", goroot),
+			hint:    hint,
+		}
+	}
+	return checkResult{status: checkPass, message: goroot}
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair problems that are safe to fix automatically")
+	RootCmd.AddCommand(doctorCmd)
+}
+
+func isVersionName(name string) bool {
+	_, err := version.Parse(name)
+	return err == nil
+}
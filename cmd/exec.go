@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var execAuto bool
+var execInstall bool
+
+var execCmd = &cobra.Command{
+	Use:     "exec [version] -- <command> [args...]",
+	Aliases: []string{"run"},
+	Short:   "Run a command under a specific Go version",
+	Long: `Run a command with GOROOT and PATH set for a specific Go version.
+
+Only the child process sees the change; the version selected with
+gover use is left alone. The command's exit code is passed through.
+
+  gover exec 1.21 -- go test ./...
+  gover exec --auto -- make build
+  gover exec --install 1.22.3 -- go version`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return fmt.Errorf("missing command; use `gover exec [version] -- <command>`")
+		}
+		if execAuto && dash != 0 {
+			return fmt.Errorf("--auto does not take a version")
+		}
+		if !execAuto && dash != 1 {
+			return fmt.Errorf("expected exactly one version before --")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dash := cmd.ArgsLenAtDash()
+		command := args[dash:]
+
+		var version string
+		var err error
+		if execAuto {
+			version, _, err = detectVersion()
+		} else if v, ok := resolveInstalled(args[0]); ok {
+			version = v
+		} else {
+			version, err = resolveVersion(args[0])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gover: failed to resolve version:", err)
+			os.Exit(1)
+		}
+
+		inst := newInstaller()
+		if !inst.IsInstalled(version) {
+			if !execInstall && !settings().AutoInstall {
+				fmt.Fprintf(os.Stderr, "gover: %s is not installed; run `gover install %s` or pass --install\n", version, version)
+				os.Exit(1)
+			}
+			archive, err := findArchive(version)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "gover: failed to look up release:", err)
+				os.Exit(1)
+			}
+			inst.Progress = os.Stderr
+			if err := inst.Install(archive); err != nil {
+				fmt.Fprintln(os.Stderr, "gover: installation failed:", err)
+				os.Exit(1)
+			}
+			refreshShims()
+		}
+
+		os.Exit(runWithGoroot(inst.Dir(version), command[0], command[1:]))
+	},
+}
+
+func init() {
+	execCmd.Flags().BoolVar(&execAuto, "auto", false, "Detect the version from .go-version, .tool-versions, go.work or go.mod")
+	execCmd.Flags().BoolVar(&execInstall, "install", false, "Install the version first if it is missing")
+	RootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// execTool runs name as a child process and returns its exit code, since
+// the process cannot be replaced on this platform.
+func execTool(name string, args []string) int {
+	c := exec.Command(name, args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(os.Stderr, "gover:", err)
+		return 127
+	}
+	return 0
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// execTool replaces the current process with name, so that signals sent to
+// a shim's pid, as editors do to cancel go list or go build, reach the tool
+// itself. It only returns if the tool cannot be started.
+func execTool(name string, args []string) int {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gover:", err)
+		return 127
+	}
+	err = syscall.Exec(path, append([]string{name}, args...), os.Environ())
+	fmt.Fprintln(os.Stderr, "gover:", err)
+	return 127
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// activeEnv records which GOROOT the shell hook applied last, so the hook
+// stays silent until the selection changes. A `gover use` in between is
+// therefore kept until the next directory with a different selection.
+const activeEnv = "__GOVER_ACTIVE"
+
+var hookShell string
+
+var hookEnvCmd = &cobra.Command{
+	Use:    "hook-env",
+	Short:  "Print environment changes for the Go version of the current directory",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !validShell(hookShell) {
+			fmt.Fprintf(os.Stderr, "gover: unsupported shell %q\n", hookShell)
+			os.Exit(1)
+		}
+
+		key, goroot, err := hookSelection()
+		if key == os.Getenv(activeEnv) || errors.Is(err, errNoVersion) {
+			return
+		}
+		if err != nil {
+			// Report once per selection instead of on every prompt.
+			fmt.Fprintln(os.Stderr, "gover:", err)
+			fmt.Print(formatEnv(hookShell, []envChange{{name: activeEnv, value: key}}))
+			return
+		}
+
+		var changes []envChange
+		for _, c := range activationEnv(goroot) {
+			if os.Getenv(c.name) != c.value {
+				changes = append(changes, c)
+			}
+		}
+		changes = append(changes, envChange{name: activeEnv, value: key})
+		fmt.Print(formatEnv(hookShell, changes))
+	},
+}
+
+// hookSelection returns the GOROOT the current directory should use and the
+// key identifying that selection. It only looks at local files and installed
+// versions so it is cheap enough to run on every prompt.
+func hookSelection() (string, string, error) {
+	version, source, err := selectVersion()
+	if err != nil {
+		return "error:" + err.Error(), "", err
+	}
+	goroot := newInstaller().Dir(version)
+	if source == "global" {
+		// Follow later `gover use` calls through the symlink.
+		goroot = newInstaller().CurrentLink()
+	}
+	return goroot, goroot, nil
+}
+
+func init() {
+	hookEnvCmd.Flags().StringVar(&hookShell, "shell", "bash", "Shell to print code for")
+	RootCmd.AddCommand(hookEnvCmd)
+}
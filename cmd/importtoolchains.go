@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mullerhx/gover/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// toolchainModule is the module the go command downloads toolchains as,
+// with versions such as v0.0.1-go1.22.3.linux-amd64.
+const toolchainModule = "golang.org/toolchain"
+
+var importModCache string
+
+var importToolchainsCmd = &cobra.Command{
+	Use:   "import-toolchains",
+	Short: "Adopt toolchains the go command downloaded into its module cache",
+	Long: `Adopt toolchains the go command downloaded into its module cache.
+
+Since Go 1.21 the go command fetches the toolchain a module asks for into
+$GOMODCACHE/golang.org/toolchain@<version> when GOTOOLCHAIN allows it. This
+copies those built for this platform into gover so that they can be used
+with gover use, the shims and the shell integration. The module cache is
+left untouched.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		modCache := importModCache
+		if modCache == "" {
+			modCache = defaultModCache()
+		}
+		toolchains, err := findToolchains(modCache)
+		if err != nil {
+			fmt.Println("Failed to read module cache:", err)
+			os.Exit(1)
+		}
+		if len(toolchains) == 0 {
+			fmt.Printf("No %s/%s toolchains found in %s.\n", runtime.GOOS, runtime.GOARCH, modCache)
+			return
+		}
+
+		inst := newInstaller()
+		imported, failed := 0, 0
+		for _, v := range sortedKeys(toolchains) {
+			if inst.IsInstalled(v) {
+				fmt.Printf("👉 %s is already installed\n", v)
+				continue
+			}
+			if err := inst.Import(v, toolchains[v]); err != nil {
+				fmt.Printf("❌ %s: %v\n", v, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ Imported %s\n", v)
+			imported++
+		}
+
+		if imported > 0 {
+			refreshShims()
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// defaultModCache returns the module cache the go command uses: GOMODCACHE,
+// or pkg/mod in the first GOPATH entry.
+func defaultModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.Join(homeDir(), "go")
+	if list := filepath.SplitList(os.Getenv("GOPATH")); len(list) > 0 && list[0] != "" {
+		gopath = list[0]
+	}
+	return filepath.Join(gopath, "pkg", "mod")
+}
+
+// findToolchains returns the unpacked toolchains for this platform in
+// modCache by Go version.
+func findToolchains(modCache string) (map[string]string, error) {
+	dir := filepath.Join(modCache, filepath.Dir(toolchainModule))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(toolchainModule) + "@"
+	suffix := "." + runtime.GOOS + "-" + runtime.GOARCH
+	toolchains := map[string]string{}
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || !e.IsDir() || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		// Strip the v0.0.1- module version prefix.
+		_, name, ok = strings.Cut(strings.TrimSuffix(name, suffix), "-")
+		if !ok {
+			continue
+		}
+		v, err := version.Parse(name)
+		if err != nil {
+			continue
+		}
+		toolchains[v.String()] = filepath.Join(dir, e.Name())
+	}
+	return toolchains, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	version.SortStrings(keys)
+	return keys
+}
+
+func init() {
+	importToolchainsCmd.Flags().StringVar(&importModCache, "modcache", "", "Module cache to import from (default $GOMODCACHE or $GOPATH/pkg/mod)")
+	RootCmd.AddCommand(importToolchainsCmd)
+}
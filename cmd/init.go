@@ -1,62 +1,117 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"os/user"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
 var initCmd = &cobra.Command{
-	Use:   "init",
+	Use:   "init [bash|zsh|fish|sh]",
 	Short: "Initialize gover environment",
-	Run: func(cmd *cobra.Command, args []string) {
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Println("Failed to get user info:", err)
-			os.Exit(1)
-		}
-		dir := filepath.Join(usr.HomeDir, ".gover")
-		releasesPath := filepath.Join(dir, "releases.json")
+	Long: `Initialize gover environment by fetching the release list.
 
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			fmt.Println("Failed to create .gover directory:", err)
-			os.Exit(1)
+With a shell name, print the shell integration instead. It defines a gover
+function that applies use, upgrade and local to the current shell and, for
+bash, zsh and fish, switches to the version a directory asks for whenever
+you change into it. Add it to your shell's startup file:
+
+  bash:  eval "$(gover-bin init bash)"     in ~/.bashrc
+  zsh:   eval "$(gover-bin init zsh)"      in ~/.zshrc
+  fish:  gover-bin init fish | source      in ~/.config/fish/config.fish
+  sh:    eval "$(gover-bin init sh)"       in ~/.profile`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: supportedShells,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			if !validShell(args[0]) {
+				fmt.Fprintf(os.Stderr, "Unsupported shell type: %q\n", args[0])
+				os.Exit(1)
+			}
+			self, err := os.Executable()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to locate gover binary:", err)
+				os.Exit(1)
+			}
+			fmt.Print(shellHook(args[0], self))
+			return
 		}
 
 		fmt.Println("Fetching release list...")
-		resp, err := http.Get("https://golang.org/dl/?mode=json&include=all")
-		if err != nil {
+		if _, err := fetchReleases(resolvePaths().Index); err != nil {
 			fmt.Println("Failed to fetch versions:", err)
 			os.Exit(1)
 		}
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close()
-		}(resp.Body)
-
-		var versions []GoVersion
-		if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-			fmt.Println("Failed to decode response:", err)
-			os.Exit(1)
-		}
-
-		file, err := os.Create(releasesPath)
-		if err != nil {
-			fmt.Println("Failed to create releases file:", err)
-			os.Exit(1)
-		}
-		defer func(file *os.File) {
-			_ = file.Close()
-		}(file)
-		if err := json.NewEncoder(file).Encode(versions); err != nil {
-			fmt.Println("Failed to write releases file:", err)
-			os.Exit(1)
-		}
 		fmt.Println("Gover initialized successfully.")
 	},
 }
+
+// shellHook returns the shell integration for shell. The gover function it
+// defines runs commands that change the environment with --shell-env and
+// evaluates their output; everything else is passed through unchanged. For
+// shells with a suitable hook it also switches versions on directory change
+// through `gover hook-env`.
+func shellHook(shell, self string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf(`function gover
+    switch "$argv[1]"
+        case use upgrade local
+            set -l __gover_env (command %[1]s $argv --shell-env fish)
+            or return $status
+            printf '%%s\n' $__gover_env | source
+        case '*'
+            command %[1]s $argv
+    end
+end
+
+function _gover_hook --on-variable PWD
+    command %[1]s hook-env --shell fish | source
+end
+_gover_hook
+`, fishQuote(self))
+
+	case "bash":
+		return shellFunction(shell, self) + fmt.Sprintf(`
+_gover_hook() {
+  local __gover_status=$?
+  eval "$(command %[1]s hook-env --shell bash)"
+  return $__gover_status
+}
+case ";${PROMPT_COMMAND:-};" in
+  *";_gover_hook;"*) ;;
+  *) PROMPT_COMMAND="_gover_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+`, shellQuote(self))
+
+	case "zsh":
+		return shellFunction(shell, self) + fmt.Sprintf(`
+_gover_hook() {
+  eval "$(command %[1]s hook-env --shell zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _gover_hook
+_gover_hook
+`, shellQuote(self))
+	}
+
+	// POSIX sh has no directory change hook; only `gover use` is wired up.
+	return shellFunction(shell, self)
+}
+
+func shellFunction(shell, self string) string {
+	return fmt.Sprintf(`gover() {
+  case "$1" in
+    use|upgrade|local)
+      __gover_env="$(command %[1]s "$@" --shell-env %[2]s)" || { __gover_status=$?; unset __gover_env; return $__gover_status; }
+      eval "$__gover_env"
+      unset __gover_env
+      ;;
+    *)
+      command %[1]s "$@"
+      ;;
+  esac
+}
+`, shellQuote(self), shell)
+}
@@ -1,129 +1,113 @@
 package cmd
 
 import (
-	"archive/tar"
-	"compress/gzip"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
-	"runtime"
-	"strings"
+	"time"
 
+	"github.com/mullerhx/gover/internal/cache"
+	"github.com/mullerhx/gover/internal/installer"
 	"github.com/spf13/cobra"
 )
 
+var downloadTimeout time.Duration
+var installFromFile string
+var installJobs int
+
 var installCmd = &cobra.Command{
-	Use:   "install [version]",
-	Short: "Download and install a specific Go version",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		version := args[0]
-		url := fmt.Sprintf("https://golang.org/dl/%s.%s-%s.tar.gz", version, runtime.GOOS, runtime.GOARCH)
-		fmt.Println("Downloading:", url)
+	Use:   "install [version...]",
+	Short: "Download and install Go versions",
+	Long: `Download and install Go versions.
 
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Println("Error downloading:", err)
-			os.Exit(1)
-		}
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close()
-		}(resp.Body)
+Each version may be an exact release such as go1.22.3 or a constraint that is
+resolved against the release index:
 
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("Failed to download: %s\n", resp.Status)
-			os.Exit(1)
-		}
+  1.22, 1.22.x     newest 1.22 patch release
+  ~1.21.3          newest 1.21 release at or above 1.21.3
+  >=1.21 <1.23     newest release within the range
+  latest, stable   newest stable release
+  oldstable        newest release of the previous minor version
 
-		outFile := filepath.Join(os.TempDir(), version+".tar.gz")
-		out, err := os.Create(outFile)
-		if err != nil {
-			fmt.Println("Failed to create temp file:", err)
-			os.Exit(1)
+Several versions, given as arguments or listed in a file with --from-file,
+are downloaded and extracted in parallel, --jobs at a time:
+
+  gover install 1.21 1.22 1.23
+  gover install --from-file versions.txt
+
+The file holds versions separated by spaces or newlines; everything after a
+# is ignored. Use - to read from standard input.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		specs := args
+		if installFromFile != "" {
+			list, err := readVersionFile(installFromFile)
+			if err != nil {
+				fmt.Println("Failed to read version file:", err)
+				os.Exit(1)
+			}
+			specs = append(specs, list...)
 		}
-		defer func(out *os.File) {
-			_ = out.Close()
-		}(out)
-
-		total := resp.ContentLength
-		progressReader := &progressReader{Reader: resp.Body, total: total}
-		if _, err := io.Copy(out, progressReader); err != nil {
-			fmt.Println("Download failed:", err)
+		if installJobs < 1 {
+			fmt.Println("--jobs must be at least 1")
 			os.Exit(1)
 		}
 
-		fmt.Println("\nExtracting...")
-		if err := extractTarGz(outFile, filepath.Join(os.Getenv("HOME"), ".gover", "versions", version)); err != nil {
-			fmt.Println("Failed to extract archive:", err)
+		switch len(specs) {
+		case 0:
+			fmt.Println("Usage: gover install <version>... or --from-file <file>")
 			os.Exit(1)
+		case 1:
+			installOne(specs[0])
+		default:
+			installMany(specs)
 		}
-
-		fmt.Println("Installation completed successfully.")
 	},
 }
 
-type progressReader struct {
-	Reader io.Reader
-	total  int64
-	read   int64
-}
+func installOne(spec string) {
+	version, err := resolveVersion(spec)
+	if err != nil {
+		fmt.Println("Failed to resolve version:", err)
+		os.Exit(1)
+	}
 
-func (pr *progressReader) Read(p []byte) (int, error) {
-	n, err := pr.Reader.Read(p)
-	pr.read += int64(n)
-	fmt.Printf("\rProgress: %.2f%%", float64(pr.read)/float64(pr.total)*100)
-	return n, err
-}
+	inst := newInstaller()
+	if inst.IsInstalled(version) {
+		fmt.Printf("Version %s is already installed.\n", version)
+		return
+	}
 
-func extractTarGz(file, targetDir string) error {
-	f, err := os.Open(file)
+	archive, err := findArchive(version)
 	if err != nil {
-		return err
+		fmt.Println("Failed to look up release:", err)
+		os.Exit(1)
 	}
-	defer func(f *os.File) {
-		_ = f.Close()
-	}(f)
 
-	gz, err := gzip.NewReader(f)
-	if err != nil {
-		return err
+	inst.Progress = os.Stdout
+	if err := inst.Install(archive); err != nil {
+		fmt.Println("Installation failed:", err)
+		os.Exit(1)
 	}
-	defer func(gz *gzip.Reader) {
-		_ = gz.Close()
-	}(gz)
-
-	tr := tar.NewReader(gz)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
 
-		path := filepath.Join(targetDir, hdr.Name)
-		if strings.HasSuffix(hdr.Name, "/") {
-			_ = os.MkdirAll(path, os.ModePerm)
-			continue
-		}
+	refreshShims()
+	fmt.Println("Installation completed successfully.")
+}
 
-		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-		outFile, err := os.Create(path)
-		if err != nil {
-			return err
-		}
-		if _, err := io.Copy(outFile, tr); err != nil {
-			_ = outFile.Close()
-			return err
-		}
-		_ = outFile.Close()
-	}
-	return nil
+func newInstaller() *installer.Installer {
+	paths := resolvePaths()
+	inst := installer.New(paths.Root)
+	inst.Versions = paths.Versions
+	inst.Current = paths.Current
+	inst.Cache = cache.New(paths.Archives)
+	inst.Mirrors = mirrors()
+	inst.Downloader.Timeout = downloadTimeout
+	inst.Offline = isOffline()
+	return inst
 }
 
 func init() {
+	installCmd.Flags().DurationVar(&downloadTimeout, "timeout", 30*time.Minute, "Overall download timeout including retries (0 for none)")
+	installCmd.Flags().StringVar(&installFromFile, "from-file", "", "Read the versions to install from a file (- for standard input)")
+	installCmd.Flags().IntVarP(&installJobs, "jobs", "j", 4, "Number of versions to download and extract at once")
 	RootCmd.AddCommand(installCmd)
 }
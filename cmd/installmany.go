@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mullerhx/gover/internal/installer"
+)
+
+// installResult is the outcome of installing one requested version.
+type installResult struct {
+	spec    string
+	version string
+	skipped bool
+	err     error
+}
+
+// installMany installs every version in specs, running up to installJobs
+// downloads and extractions at once, and prints a summary. It exits with 1
+// if any of them failed.
+func installMany(specs []string) {
+	// Resolve and look up everything first: this may refresh the release
+	// index, which the installs must not do concurrently.
+	var results []*installResult
+	var archives []installer.Archive
+	var pending []*installResult
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		r := &installResult{spec: spec}
+		version, err := resolveVersion(spec)
+		if err != nil {
+			r.err = err
+			results = append(results, r)
+			continue
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		r.version = version
+		results = append(results, r)
+
+		if newInstaller().IsInstalled(version) {
+			r.skipped = true
+			continue
+		}
+		archive, err := findArchive(version)
+		if err != nil {
+			r.err = err
+			continue
+		}
+		archives = append(archives, archive)
+		pending = append(pending, r)
+	}
+
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for n, r := range pending {
+			names[n] = r.version
+		}
+		board := newProgressBoard(os.Stdout, names)
+
+		sem := make(chan struct{}, installJobs)
+		var wg sync.WaitGroup
+		for n, r := range pending {
+			inst := newInstaller()
+			inst.Progress = board.Writer(r.version)
+			wg.Add(1)
+			go func(archive installer.Archive) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				r.err = inst.Install(archive)
+				if r.err != nil {
+					board.Finish(r.version, "❌ failed")
+				} else {
+					board.Finish(r.version, "✅ installed")
+				}
+			}(archives[n])
+		}
+		wg.Wait()
+		fmt.Println()
+	}
+
+	installed, failed := 0, 0
+	fmt.Println("Summary:")
+	for _, r := range results {
+		name := r.version
+		if name == "" {
+			name = r.spec
+		}
+		switch {
+		case r.err != nil:
+			fmt.Printf("  ❌ %s: %v\n", name, r.err)
+			failed++
+		case r.skipped:
+			fmt.Printf("  👉 %s is already installed\n", name)
+		default:
+			fmt.Printf("  ✅ %s installed\n", name)
+			installed++
+		}
+	}
+
+	if installed > 0 {
+		refreshShims()
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d versions failed to install.\n", failed, len(results))
+		os.Exit(1)
+	}
+}
+
+// readVersionFile returns the versions listed in path, or on standard input
+// for "-". Versions are separated by white space and # starts a comment.
+func readVersionFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		versions = append(versions, strings.Fields(line)...)
+	}
+	return versions, nil
+}
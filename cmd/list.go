@@ -1,28 +1,30 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"os/user"
-	"path/filepath"
 	"runtime"
-	"sort"
-	"strings"
 
+	"github.com/mullerhx/gover/internal/version"
 	"github.com/spf13/cobra"
 )
 
 type GoVersion struct {
-	Version string `json:"version"`
-	Stable  bool   `json:"stable"`
-	Files   []struct {
-		Filename string `json:"filename"`
-		OS       string `json:"os"`
-		Arch     string `json:"arch"`
-	} `json:"files"`
+	Version string   `json:"version"`
+	Stable  bool     `json:"stable"`
+	Files   []GoFile `json:"files"`
+}
+
+// GoFile describes a single downloadable file of a Go release as published
+// by the go.dev JSON index.
+type GoFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	Sha256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
 }
 
 var all bool
@@ -31,131 +33,133 @@ var forceFetch bool
 
 var installedOnly bool = false
 
+// listEntry is a version as printed by `gover list --output json`.
+type listEntry struct {
+	Version   string   `json:"version"`
+	Stable    bool     `json:"stable"`
+	Installed bool     `json:"installed"`
+	Active    bool     `json:"active"`
+	GOROOT    string   `json:"goroot,omitempty"`
+	Files     []GoFile `json:"files,omitempty"`
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available Go versions",
 	Run: func(cmd *cobra.Command, args []string) {
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Println("Failed to get user info:", err)
-			os.Exit(1)
-		}
+		inst := newInstaller()
+		active := activeVersion()
 
-		// In the Run function, add this block before fetching remote versions
 		if installedOnly {
-			usr, err := user.Current()
-			if err != nil {
-				fmt.Println("Failed to get user info:", err)
-				os.Exit(1)
-			}
-			versionsDir := filepath.Join(usr.HomeDir, ".gover", "versions")
-			entries, err := os.ReadDir(versionsDir)
-			if err != nil {
-				fmt.Println("Failed to read installed versions:", err)
-				os.Exit(1)
+			installed := installedVersions()
+			if _, err := os.Stat(inst.VersionsDir()); err != nil && !os.IsNotExist(err) {
+				failf("Failed to read installed versions: %v", err)
 			}
-			var installed []string
-			for _, entry := range entries {
-				if entry.IsDir() {
-					installed = append(installed, entry.Name())
+			version.Sort(installed)
+			if !jsonOutput() {
+				for _, v := range installed {
+					fmt.Println(v)
 				}
+				return
 			}
-			sort.Strings(installed)
+			entries := []listEntry{}
 			for _, v := range installed {
-				fmt.Println(v)
+				entries = append(entries, listEntry{
+					Version:   v.String(),
+					Stable:    !v.IsPrerelease(),
+					Installed: true,
+					Active:    v.String() == active,
+					GOROOT:    inst.Dir(v.String()),
+				})
 			}
+			printJSON(entries)
 			return
 		}
 
-		releasesPath := filepath.Join(usr.HomeDir, ".gover", "releases.json")
-		var versions []GoVersion
-
-		if forceFetch || !fileExists(releasesPath) {
-			resp, err := http.Get("https://golang.org/dl/?mode=json&include=all")
-			if err != nil {
-				fmt.Println("Failed to fetch versions:", err)
-				os.Exit(1)
-			}
-			defer func(Body io.ReadCloser) {
-				_ = Body.Close()
-			}(resp.Body)
-
-			if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-				fmt.Println("Failed to decode response:", err)
-				os.Exit(1)
-			}
-
-			if err := os.MkdirAll(filepath.Dir(releasesPath), 0755); err != nil {
-				fmt.Println("Failed to create .gover directory:", err)
-				os.Exit(1)
-			}
+		versions, err := loadReleases(forceFetch)
+		if err != nil {
+			failf("Failed to load releases: %v", err)
+		}
 
-			file, err := os.Create(releasesPath)
-			if err != nil {
-				fmt.Println("Failed to create releases file:", err)
-				os.Exit(1)
-			}
-			defer func(file *os.File) {
-				_ = file.Close()
-			}(file)
-			_ = json.NewEncoder(file).Encode(versions)
-		} else {
-			file, err := os.Open(releasesPath)
+		var filter version.Version
+		if majorFilter != "" {
+			filter, err = version.Parse(majorFilter)
 			if err != nil {
-				fmt.Println("Failed to read local releases file:", err)
-				os.Exit(1)
-			}
-			defer func(file *os.File) {
-				_ = file.Close()
-			}(file)
-			if err := json.NewDecoder(file).Decode(&versions); err != nil {
-				fmt.Println("Failed to decode local releases file:", err)
-				os.Exit(1)
+				failf("Invalid major version: %v", err)
 			}
 		}
 
-		versionMap := map[string][]string{}
+		versionMap := map[string][]version.Version{}
+		hostFiles := map[string][]GoFile{}
+		stable := map[string]bool{}
 
 		for _, v := range versions {
 			if !all && !v.Stable {
 				continue
 			}
+			parsed, err := version.Parse(v.Version)
+			if err != nil {
+				continue
+			}
+			if majorFilter != "" && !parsed.HasPrefix(filter) {
+				continue
+			}
 			for _, f := range v.Files {
 				if f.OS == runtime.GOOS && f.Arch == runtime.GOARCH {
-					parts := strings.Split(v.Version, ".")
-					if len(parts) < 2 {
-						continue
-					}
-					prefix := strings.Join(parts[:2], ".")
-					cleanPrefix := strings.TrimPrefix(prefix, "go")
-					if majorFilter != "" && !strings.HasPrefix(cleanPrefix, majorFilter) {
-						continue
-					}
-					versionMap[prefix] = append(versionMap[prefix], v.Version)
+					hostFiles[parsed.String()] = append(hostFiles[parsed.String()], f)
 				}
 			}
+			stable[parsed.String()] = v.Stable
+			if len(hostFiles[parsed.String()]) > 0 {
+				prefix := parsed.MinorString()
+				versionMap[prefix] = append(versionMap[prefix], parsed)
+			}
 		}
 
 		var keys []string
 		for k := range versionMap {
 			keys = append(keys, k)
 		}
-		sort.Strings(keys)
+		version.SortStrings(keys)
 
+		entries := []listEntry{}
 		for _, prefix := range keys {
 			vers := versionMap[prefix]
-			sort.Strings(vers)
-			limit := 20
-			if len(vers) < 20 {
+			version.Sort(vers)
+			limit := settings().ListLimitOr(20)
+			if limit == 0 || len(vers) < limit {
 				limit = len(vers)
 			}
 			for _, v := range vers[len(vers)-limit:] {
-				fmt.Println(v)
+				if !jsonOutput() {
+					fmt.Println(v)
+					continue
+				}
+				entries = append(entries, listEntry{
+					Version:   v.String(),
+					Stable:    stable[v.String()],
+					Installed: inst.IsInstalled(v.String()),
+					Active:    v.String() == active,
+					Files:     hostFiles[v.String()],
+				})
 			}
 		}
+		if jsonOutput() {
+			printJSON(entries)
+		}
 	},
 }
 
+// activeVersion returns the version `gover current` reports for the current
+// directory, or "" when none is selected.
+func activeVersion() string {
+	version, _, err := selectVersion()
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
 func init() {
 	listCmd.Flags().BoolVarP(&all, "all", "a", false, "Include unstable versions (beta, rc)")
 	listCmd.Flags().BoolVarP(&installedOnly, "installed", "i", false, "List only installed Go versions")
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mullerhx/gover/internal/project"
+	"github.com/mullerhx/gover/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var unsetLocal bool
+
+var localCmd = &cobra.Command{
+	Use:   "local [version]",
+	Short: "Pin the Go version for the current directory",
+	Long: `Pin the Go version for the current directory by writing a .go-version file.
+
+Without arguments, print the version pinned for the current directory and
+the file it comes from.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := messageWriter()
+		dir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintln(out, "Failed to get working directory:", err)
+			os.Exit(1)
+		}
+
+		if unsetLocal {
+			path := filepath.Join(dir, project.GoVersionFile)
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintln(out, "Failed to remove version file:", err)
+				os.Exit(1)
+			}
+			fmt.Fprintln(out, "✅ Removed", path)
+			if shellEnv != "" && fileExists(newInstaller().CurrentLink()) {
+				printActivation(newInstaller().CurrentLink())
+			}
+			return
+		}
+
+		if len(args) == 0 {
+			req, err := project.Detect(dir)
+			if err != nil || !req.Exact {
+				fmt.Fprintln(out, "No local Go version set. Use `gover local <version>`.")
+				os.Exit(1)
+			}
+			fmt.Fprintf(out, "%s (set by %s)\n", req.Version, requestSource(req))
+			return
+		}
+
+		if _, err := version.ParseConstraint(args[0]); err != nil {
+			fmt.Fprintln(out, "Invalid version:", err)
+			os.Exit(1)
+		}
+		path, err := project.WriteVersionFile(dir, args[0])
+		if err != nil {
+			fmt.Fprintln(out, "Failed to write version file:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "✅ Pinned Go %s in %s\n", args[0], path)
+
+		if shellEnv != "" {
+			installed, ok := resolveInstalled(args[0])
+			if !ok {
+				fmt.Fprintf(out, "Go %s is not installed yet. Run `gover install %s`.\n", args[0], args[0])
+				return
+			}
+			printActivation(newInstaller().Dir(installed))
+		}
+	},
+}
+
+func init() {
+	localCmd.Flags().BoolVar(&unsetLocal, "unset", false, "Remove the .go-version file from the current directory")
+	addShellEnvFlag(localCmd)
+	RootCmd.AddCommand(localCmd)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/mullerhx/gover/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var migrateFrom string
+
+var migrateRootCmd = &cobra.Command{
+	Use:   "migrate-root [dir]",
+	Short: "Move installed versions and caches to a new location",
+	Long: `Move installed versions, shims, downloaded archives and the release index
+to a new location and repoint the current symlink. The files are taken from
+--from, or else from ~/.gover if it exists, or else from wherever gover
+keeps them now.
+
+With dir, everything is kept below dir and the root setting is updated so
+that gover finds it there. Without dir, the files move to GOVER_ROOT or the
+root setting if one is set, and otherwise to the XDG base directories:
+versions and shims to $XDG_DATA_HOME/gover, the current link to
+$XDG_STATE_HOME/gover and archives and the release index to
+$XDG_CACHE_HOME/gover.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		from := resolvePaths()
+		if migrateFrom != "" {
+			from = rootPaths(expandHome(migrateFrom))
+		} else if fileExists(legacyRoot()) {
+			from = rootPaths(legacyRoot())
+		}
+		if !fileExists(from.Versions) && !fileExists(from.Index) {
+			fmt.Printf("❌ Nothing to migrate: %s holds no versions.\n", describePaths(from))
+			os.Exit(1)
+		}
+
+		var to goverPaths
+		switch {
+		case len(args) == 1:
+			dir, err := filepath.Abs(expandHome(args[0]))
+			if err != nil {
+				fmt.Println("Failed to resolve target directory:", err)
+				os.Exit(1)
+			}
+			to = rootPaths(dir)
+		case os.Getenv(rootEnv) != "":
+			to = rootPaths(expandHome(os.Getenv(rootEnv)))
+		case settings().Root != "":
+			to = rootPaths(expandHome(settings().Root))
+		default:
+			to = xdgPaths()
+		}
+		if filepath.Clean(to.Versions) == filepath.Clean(from.Versions) {
+			fmt.Printf("❌ Gover already uses %s.\n", describePaths(to))
+			os.Exit(1)
+		}
+
+		skipped, err := migrateTree(from, to)
+		if err != nil {
+			fmt.Println("Failed to migrate:", err)
+			os.Exit(1)
+		}
+		for _, path := range skipped {
+			fmt.Printf("⚠️  Kept %s: it already exists at the new location\n", path)
+		}
+
+		if len(args) == 1 && os.Getenv(rootEnv) != to.Root {
+			if err := saveRootSetting(to.Root); err != nil {
+				fmt.Println("Failed to update the root setting:", err)
+				os.Exit(1)
+			}
+		}
+		if len(skipped) == 0 {
+			removeEmptyDirs(from)
+		}
+		refreshShims()
+
+		fmt.Println("✅ Moved", describePaths(from), "to", describePaths(to))
+		fmt.Println("👉 Update PATH in your shell profile if it lists", filepath.Join(from.Current, "bin"), "or", from.Shims)
+		if os.Getenv(rootEnv) != "" && os.Getenv(rootEnv) != to.Root {
+			fmt.Printf("👉 %s is set to %s; change or unset it to use the new location.\n", rootEnv, os.Getenv(rootEnv))
+		}
+	},
+}
+
+// migrateTree moves every part of the gover tree from one layout to the
+// other and returns the source paths left in place because the target
+// already had them.
+func migrateTree(from, to goverPaths) ([]string, error) {
+	var skipped []string
+	for _, dir := range [][2]string{
+		{from.Versions, to.Versions},
+		{from.Shims, to.Shims},
+		{from.Archives, to.Archives},
+	} {
+		entries, err := os.ReadDir(dir[0])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return skipped, err
+		}
+		if err := os.MkdirAll(dir[1], 0755); err != nil {
+			return skipped, err
+		}
+		for _, e := range entries {
+			src := filepath.Join(dir[0], e.Name())
+			dst := filepath.Join(dir[1], e.Name())
+			if strings.HasPrefix(e.Name(), ".staging-") {
+				// Interrupted installs are not worth moving.
+				_ = os.RemoveAll(src)
+				continue
+			}
+			if _, err := os.Lstat(dst); err == nil {
+				skipped = append(skipped, src)
+				continue
+			}
+			if err := moveTree(src, dst); err != nil {
+				return skipped, err
+			}
+		}
+		if err := os.Remove(dir[0]); err != nil && !os.IsNotExist(err) {
+			skipped = append(skipped, dir[0])
+		}
+	}
+
+	for _, file := range [][2]string{
+		{from.Index, to.Index},
+		{indexMetaPath(from.Index), indexMetaPath(to.Index)},
+	} {
+		if !fileExists(file[0]) {
+			continue
+		}
+		if fileExists(file[1]) {
+			_ = os.Remove(file[0])
+		} else if err := os.MkdirAll(filepath.Dir(file[1]), 0755); err != nil {
+			return skipped, err
+		} else if err := moveTree(file[0], file[1]); err != nil {
+			return skipped, err
+		}
+	}
+
+	if target, err := os.Readlink(from.Current); err == nil {
+		if rel, err := filepath.Rel(from.Versions, target); err == nil && !strings.HasPrefix(rel, "..") {
+			target = filepath.Join(to.Versions, rel)
+		}
+		if err := os.MkdirAll(filepath.Dir(to.Current), 0755); err != nil {
+			return skipped, err
+		}
+		_ = os.Remove(to.Current)
+		if err := os.Symlink(target, to.Current); err != nil {
+			return skipped, fmt.Errorf("failed to create symlink: %w", err)
+		}
+		_ = os.Remove(from.Current)
+	}
+	return skipped, nil
+}
+
+// moveTree renames src to dst, copying instead when they are on different
+// file systems.
+func moveTree(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := installer.CopyTree(src, dst, 0); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func saveRootSetting(root string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	c.Root = root
+	return c.Save(path)
+}
+
+// removeEmptyDirs removes the old gover directories once everything has
+// been moved out, leaving them alone if anything unexpected is still inside.
+func removeEmptyDirs(p goverPaths) {
+	dirs := []string{p.Root}
+	if p.Root == "" {
+		dirs = []string{filepath.Dir(p.Versions), filepath.Dir(p.Current), filepath.Dir(p.Index)}
+	}
+	for _, dir := range dirs {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("⚠️  Left %s in place: %v\n", dir, err)
+		}
+	}
+}
+
+func describePaths(p goverPaths) string {
+	if p.Root != "" {
+		return p.Root
+	}
+	return fmt.Sprintf("%s, %s and %s", filepath.Dir(p.Versions), filepath.Dir(p.Current), filepath.Dir(p.Index))
+}
+
+func init() {
+	migrateRootCmd.Flags().StringVar(&migrateFrom, "from", "", "Existing gover root to move (default ~/.gover)")
+	RootCmd.AddCommand(migrateRootCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/mullerhx/gover/internal/installer"
+)
+
+const (
+	// mirrorEnv lists base URLs serving Go release archives, separated by
+	// commas and tried in order. It replaces https://go.dev/dl/.
+	mirrorEnv = "GOVER_MIRROR"
+	// indexURLEnv lists URLs of the JSON release index, separated by commas
+	// and tried in order.
+	indexURLEnv = "GOVER_INDEX_URL"
+)
+
+// indexQuery selects the full JSON release index on go.dev and on mirrors
+// that proxy it.
+const indexQuery = "?mode=json&include=all"
+
+// mirrors returns the base URLs to download release archives from:
+// GOVER_MIRROR, then the mirrors setting, then go.dev.
+func mirrors() []string {
+	if list := config.SplitList(os.Getenv(mirrorEnv)); len(list) > 0 {
+		return list
+	}
+	if list := settings().Mirrors; len(list) > 0 {
+		return list
+	}
+	return []string{installer.DefaultBaseURL}
+}
+
+// indexURLs returns the URLs to fetch the release index from:
+// GOVER_INDEX_URL, then the index_urls setting. Without either, each
+// configured mirror is asked for the index the way go.dev serves it.
+func indexURLs() []string {
+	if list := config.SplitList(os.Getenv(indexURLEnv)); len(list) > 0 {
+		return list
+	}
+	if list := settings().IndexURLs; len(list) > 0 {
+		return list
+	}
+	list := mirrors()
+	if len(list) == 1 && list[0] == installer.DefaultBaseURL {
+		return []string{releasesURL}
+	}
+	urls := make([]string, len(list))
+	for i, mirror := range list {
+		urls[i] = strings.TrimSuffix(mirror, "/") + "/" + indexQuery
+	}
+	return urls
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// offlineEnv turns on offline mode like --offline.
+const offlineEnv = "GOVER_OFFLINE"
+
+var offlineFlag bool
+
+var errOffline = errors.New("cannot refresh the release index in offline mode")
+
+// isOffline reports whether gover must not touch the network. Only the
+// cached release index, the archive cache and installed versions are used.
+func isOffline() bool {
+	if offlineFlag {
+		return true
+	}
+	on, _ := strconv.ParseBool(os.Getenv(offlineEnv))
+	return on
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "Never use the network; rely on the cached index, archives and installed versions (or set GOVER_OFFLINE=1)")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat is set by the global --output flag. Commands that support
+// machine-readable output check jsonOutput; the others ignore it.
+var outputFormat string
+
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to encode output:", err)
+		os.Exit(1)
+	}
+}
+
+// failf reports an error and exits. In JSON mode the message goes to stderr
+// so that stdout only ever carries JSON.
+func failf(format string, args ...any) {
+	if jsonOutput() {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	} else {
+		fmt.Printf(format+"\n", args...)
+	}
+	os.Exit(1)
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format for list, current and detect: text or json")
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("output") && settings().Output != "" {
+			outputFormat = settings().Output
+		}
+		if outputFormat != outputText && outputFormat != outputJSON {
+			return fmt.Errorf("unsupported output format %q; expected text or json", outputFormat)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// rootEnv keeps everything gover writes in a single directory, the way
+// ~/.gover is laid out.
+const rootEnv = "GOVER_ROOT"
+
+// goverPaths are the locations gover reads and writes.
+type goverPaths struct {
+	// Root is the single directory holding everything, or "" when the
+	// files are split across the XDG directories.
+	Root     string
+	Versions string
+	Current  string
+	Shims    string
+	Archives string
+	Index    string
+}
+
+// resolvePaths decides where gover keeps its files, in order of precedence:
+// GOVER_ROOT, the root setting, an existing ~/.gover, and otherwise the XDG
+// base directories. With XDG, installed versions and shims are data, the
+// current link is state, and downloaded archives and the release index are
+// cache.
+func resolvePaths() goverPaths {
+	if root := os.Getenv(rootEnv); root != "" {
+		return rootPaths(expandHome(root))
+	}
+	if root := settings().Root; root != "" {
+		return rootPaths(expandHome(root))
+	}
+	if legacy := legacyRoot(); fileExists(legacy) {
+		return rootPaths(legacy)
+	}
+	return xdgPaths()
+}
+
+// rootPaths lays out everything below root.
+func rootPaths(root string) goverPaths {
+	return goverPaths{
+		Root:     root,
+		Versions: filepath.Join(root, "versions"),
+		Current:  filepath.Join(root, "current"),
+		Shims:    filepath.Join(root, "shims"),
+		Archives: filepath.Join(root, "cache"),
+		Index:    filepath.Join(root, "releases.json"),
+	}
+}
+
+func xdgPaths() goverPaths {
+	data := xdgDir("XDG_DATA_HOME", ".local", "share")
+	state := xdgDir("XDG_STATE_HOME", ".local", "state")
+	cache := xdgDir("XDG_CACHE_HOME", ".cache")
+	return goverPaths{
+		Versions: filepath.Join(data, "versions"),
+		Current:  filepath.Join(state, "current"),
+		Shims:    filepath.Join(data, "shims"),
+		Archives: filepath.Join(cache, "archives"),
+		Index:    filepath.Join(cache, "releases.json"),
+	}
+}
+
+// xdgDir returns the gover directory below the base directory named by env,
+// or below its default relative to the home directory.
+func xdgDir(env string, def ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gover")
+	}
+	return filepath.Join(append([]string{homeDir()}, append(def, "gover")...)...)
+}
+
+// legacyRoot returns ~/.gover, where gover kept everything before the XDG
+// split.
+func legacyRoot() string {
+	return filepath.Join(homeDir(), ".gover")
+}
+
+func homeDir() string {
+	if usr, err := user.Current(); err == nil {
+		return usr.HomeDir
+	}
+	return os.Getenv("HOME")
+}
+
+// managed reports whether path lies inside a directory gover manages.
+func (p goverPaths) managed(path string) bool {
+	for _, dir := range []string{p.Versions, p.Current, p.Shims} {
+		if path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressBarWidth = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressBoard shows one status line per install while several run at
+// once. On a terminal the lines are redrawn in place, with download
+// progress drawn as a bar. Otherwise every finished line is printed with
+// its name in front, so that CI logs stay readable.
+type progressBoard struct {
+	mu     sync.Mutex
+	out    io.Writer
+	tty    bool
+	names  []string
+	width  int
+	status map[string]string
+	drawn  int
+	last   time.Time
+}
+
+func newProgressBoard(out *os.File, names []string) *progressBoard {
+	b := &progressBoard{
+		out:    out,
+		tty:    isTerminal(out),
+		names:  names,
+		status: map[string]string{},
+	}
+	for _, name := range names {
+		b.width = max(b.width, len(name))
+		b.status[name] = "waiting"
+	}
+	b.redraw(true)
+	return b
+}
+
+// Writer returns a writer for the progress output of name, as written by
+// installer.Installer.
+func (b *progressBoard) Writer(name string) io.Writer {
+	return &boardLine{board: b, name: name}
+}
+
+// Finish sets the final status of name.
+func (b *progressBoard) Finish(name, status string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status[name] = status
+	if !b.tty {
+		fmt.Fprintf(b.out, "%-*s  %s\n", b.width, name, status)
+	}
+	b.redraw(true)
+}
+
+// redraw repaints every line on a terminal, at most every progressInterval
+// unless force is set. b.mu must be held.
+func (b *progressBoard) redraw(force bool) {
+	if !b.tty || (!force && time.Since(b.last) < progressInterval) {
+		return
+	}
+	b.last = time.Now()
+	if b.drawn > 0 {
+		fmt.Fprintf(b.out, "\033[%dA", b.drawn)
+	}
+	for _, name := range b.names {
+		fmt.Fprintf(b.out, "\r\033[K%-*s  %s\n", b.width, name, renderStatus(b.status[name]))
+	}
+	b.drawn = len(b.names)
+}
+
+// renderStatus draws the "Progress: 42.00%" lines of the downloader as a
+// bar and leaves other lines as they are.
+func renderStatus(status string) string {
+	rest, ok := strings.CutPrefix(status, "Progress: ")
+	if !ok {
+		return status
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(rest, "%"), 64)
+	if err != nil {
+		return status
+	}
+	filled := min(int(percent/100*progressBarWidth), progressBarWidth)
+	return fmt.Sprintf("[%s%s] %5.1f%%", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), percent)
+}
+
+// boardLine turns the output of one install into status updates. Text after
+// a carriage return replaces the line, as the downloader's progress does.
+type boardLine struct {
+	board   *progressBoard
+	name    string
+	partial []byte
+}
+
+func (l *boardLine) Write(p []byte) (int, error) {
+	b := l.board
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	finished := false
+	for _, c := range p {
+		switch c {
+		case '\r':
+			l.partial = l.partial[:0]
+		case '\n':
+			if len(l.partial) > 0 {
+				b.status[l.name] = string(l.partial)
+				if !b.tty {
+					fmt.Fprintf(b.out, "%-*s  %s\n", b.width, l.name, l.partial)
+				}
+				finished = true
+			}
+			l.partial = l.partial[:0]
+		default:
+			l.partial = append(l.partial, c)
+		}
+	}
+	if len(l.partial) > 0 {
+		b.status[l.name] = string(l.partial)
+	}
+	b.redraw(finished)
+	return len(p), nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
-	"os/user"
 	"path/filepath"
 	"strings"
 )
@@ -13,11 +12,7 @@ var promptCmd = &cobra.Command{
 	Use:   "prompt",
 	Short: "Output current Go version for shell prompt",
 	Run: func(cmd *cobra.Command, args []string) {
-		usr, err := user.Current()
-		if err != nil {
-			os.Exit(0) // Silent fail for prompt integration
-		}
-		current := filepath.Join(usr.HomeDir, ".gover", "current")
+		current := newInstaller().CurrentLink()
 		resolved, err := filepath.EvalSymlinks(current)
 		if err != nil {
 			os.Exit(0)
@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mullerhx/gover/internal/installer"
+	"github.com/mullerhx/gover/internal/version"
+)
+
+// releasesURL is the release index on go.dev, used unless mirrors are
+// configured.
+const releasesURL = "https://go.dev/dl/?mode=json&include=all"
+
+// defaultIndexTTL is how long the cached release index is used without
+// asking the server whether it changed.
+const defaultIndexTTL = 24 * time.Hour
+
+var errNotModified = errors.New("release index not modified")
+
+// indexMeta records where and when the cached release index was fetched so
+// that it can be revalidated with a conditional request.
+type indexMeta struct {
+	URL          string    `json:"url"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// loadReleases returns the release index. The cached copy is used while it
+// is younger than the index_ttl setting; after that the server is asked
+// whether it changed. When that fails the stale copy is used with a
+// warning. With refresh, the index is fetched unconditionally. In offline
+// mode the cached copy is used however old it is.
+func loadReleases(refresh bool) ([]GoVersion, error) {
+	releasesPath := resolvePaths().Index
+
+	if isOffline() && !fileExists(releasesPath) {
+		return nil, fmt.Errorf("release index is not cached; run `gover init` once without --offline")
+	}
+	if refresh || !fileExists(releasesPath) {
+		return fetchReleases(releasesPath)
+	}
+
+	meta := readIndexMeta(releasesPath)
+	if isOffline() || time.Since(meta.FetchedAt) < indexTTL() {
+		return readReleases(releasesPath)
+	}
+	versions, err := refreshReleases(releasesPath, meta)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not refresh the release index, using the copy from %s ago: %v\n",
+			formatAge(time.Since(meta.FetchedAt)), err)
+		return readReleases(releasesPath)
+	}
+	return versions, nil
+}
+
+func readReleases(releasesPath string) ([]GoVersion, error) {
+	file, err := os.Open(releasesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release cache: %w", err)
+	}
+	defer file.Close()
+
+	var versions []GoVersion
+	if err := json.NewDecoder(file).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("failed to decode release cache: %w", err)
+	}
+	return versions, nil
+}
+
+// fetchReleases downloads the release index from the first index URL that
+// serves it and stores it at releasesPath.
+func fetchReleases(releasesPath string) ([]GoVersion, error) {
+	return refreshReleases(releasesPath, indexMeta{})
+}
+
+// refreshReleases fetches the release index, sending the validators in meta
+// to the URL they came from, and stores the result at releasesPath.
+func refreshReleases(releasesPath string, meta indexMeta) ([]GoVersion, error) {
+	if isOffline() {
+		return nil, errOffline
+	}
+	var versions []GoVersion
+	var fetched indexMeta
+	var err error
+	for _, url := range indexURLs() {
+		if versions, fetched, err = fetchIndex(url, meta); err == nil || errors.Is(err, errNotModified) {
+			break
+		}
+	}
+	if errors.Is(err, errNotModified) {
+		versions, err = readReleases(releasesPath)
+		if err != nil {
+			return nil, err
+		}
+		meta.FetchedAt = time.Now()
+		return versions, writeIndexMeta(releasesPath, meta)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(releasesPath, data); err != nil {
+		return nil, fmt.Errorf("failed to write releases file: %w", err)
+	}
+	return versions, writeIndexMeta(releasesPath, fetched)
+}
+
+// fetchIndex fetches the release index from url. It returns errNotModified
+// when meta came from url and the server reports no change since.
+func fetchIndex(url string, meta indexMeta) ([]GoVersion, indexMeta, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, indexMeta{}, err
+	}
+	if meta.URL == url {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, indexMeta{}, fmt.Errorf("failed to fetch versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && meta.URL == url {
+		return nil, indexMeta{}, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, indexMeta{}, fmt.Errorf("failed to fetch versions from %s: %s", url, resp.Status)
+	}
+
+	var versions []GoVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, indexMeta{}, fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return versions, indexMeta{
+		URL:          url,
+		FetchedAt:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// indexMetaPath returns where the metadata of the index at releasesPath is
+// kept.
+func indexMetaPath(releasesPath string) string {
+	return strings.TrimSuffix(releasesPath, ".json") + ".meta.json"
+}
+
+// readIndexMeta returns the metadata of the cached index. Indexes written
+// before metadata was recorded are dated by their modification time.
+func readIndexMeta(releasesPath string) indexMeta {
+	var meta indexMeta
+	data, err := os.ReadFile(indexMetaPath(releasesPath))
+	if err == nil && json.Unmarshal(data, &meta) == nil {
+		return meta
+	}
+	if info, err := os.Stat(releasesPath); err == nil {
+		meta.FetchedAt = info.ModTime()
+	}
+	return meta
+}
+
+func writeIndexMeta(releasesPath string, meta indexMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(indexMetaPath(releasesPath), data)
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so that an interrupted or concurrent refresh never leaves
+// a truncated file behind for other commands to read.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// indexTTL returns the index_ttl setting, or defaultIndexTTL.
+func indexTTL() time.Duration {
+	if ttl, err := time.ParseDuration(settings().IndexTTL); err == nil {
+		return ttl
+	}
+	return defaultIndexTTL
+}
+
+// formatAge renders d coarsely, the way a person would say it.
+func formatAge(d time.Duration) string {
+	n, unit := 0, ""
+	switch {
+	case d < time.Minute:
+		return "less than a minute"
+	case d < time.Hour:
+		n, unit = int(d.Minutes()), "minute"
+	case d < 48*time.Hour:
+		n, unit = int(d.Hours()), "hour"
+	default:
+		n, unit = int(d.Hours()/24), "day"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
+
+// findArchive returns the archive published for version on the host
+// platform. The release index is refreshed once when it does not list the
+// archive yet or was written before checksums were recorded.
+func findArchive(version string) (installer.Archive, error) {
+	file, err := findArchiveFile(version)
+	if err != nil {
+		return installer.Archive{}, err
+	}
+	return installer.Archive{
+		Version:  version,
+		Filename: file.Filename,
+		Sha256:   file.Sha256,
+		Size:     file.Size,
+	}, nil
+}
+
+func findArchiveFile(version string) (GoFile, error) {
+	versions, err := loadReleases(false)
+	if err != nil {
+		return GoFile{}, err
+	}
+
+	file, ok := archiveFor(versions, version)
+	if (!ok || file.Sha256 == "") && !isOffline() {
+		if versions, err = loadReleases(true); err != nil {
+			return GoFile{}, err
+		}
+		file, ok = archiveFor(versions, version)
+	}
+	if !ok && isOffline() {
+		return GoFile{}, fmt.Errorf("%s is not in the cached release index", version)
+	}
+	if !ok {
+		return GoFile{}, fmt.Errorf("no archive for %s on %s/%s", version, runtime.GOOS, runtime.GOARCH)
+	}
+	if file.Sha256 == "" {
+		return GoFile{}, fmt.Errorf("no checksum published for %s", file.Filename)
+	}
+	return file, nil
+}
+
+func archiveFor(versions []GoVersion, version string) (GoFile, bool) {
+	for _, v := range versions {
+		if v.Version != version {
+			continue
+		}
+		for _, f := range v.Files {
+			if f.Kind == "archive" && f.OS == runtime.GOOS && f.Arch == runtime.GOARCH &&
+				strings.HasSuffix(f.Filename, ".tar.gz") {
+				return f, true
+			}
+		}
+	}
+	return GoFile{}, false
+}
+
+// releaseVersions parses the names of all releases in the index.
+func releaseVersions(versions []GoVersion) []version.Version {
+	var parsed []version.Version
+	for _, v := range versions {
+		if p, err := version.Parse(v.Version); err == nil {
+			parsed = append(parsed, p)
+		}
+	}
+	return parsed
+}
+
+// resolveVersion resolves a version constraint such as "1.22", "~1.21" or
+// "oldstable" against the release index. Exact versions are returned as is
+// without reading the index.
+func resolveVersion(spec string) (string, error) {
+	c, err := version.ParseConstraint(spec)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := c.Exact(); ok {
+		return v.String(), nil
+	}
+
+	versions, err := loadReleases(false)
+	if err != nil {
+		return "", err
+	}
+	v, ok := c.Select(releaseVersions(versions))
+	if !ok {
+		return "", fmt.Errorf("no Go release matches %q", spec)
+	}
+	return v.String(), nil
+}
+
+// resolveInstalled resolves a version constraint against the installed
+// versions only.
+func resolveInstalled(spec string) (string, bool) {
+	c, err := version.ParseConstraint(spec)
+	if err != nil || c.NeedsIndex() {
+		return "", false
+	}
+	v, ok := c.Select(installedVersions())
+	if !ok {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// installedVersions returns every complete install below the versions
+// directory.
+func installedVersions() []version.Version {
+	inst := newInstaller()
+	entries, err := os.ReadDir(inst.VersionsDir())
+	if err != nil {
+		return nil
+	}
+	var installed []version.Version
+	for _, entry := range entries {
+		v, err := version.Parse(entry.Name())
+		if err != nil || !entry.IsDir() || !inst.IsInstalled(entry.Name()) {
+			continue
+		}
+		installed = append(installed, v)
+	}
+	return installed
+}
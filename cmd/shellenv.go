@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// shellEnv is set by the shell function from `gover init <shell>`. Commands
+// that change the environment then print only code for that shell on stdout
+// and send everything meant for humans to stderr.
+var shellEnv string
+
+var supportedShells = []string{"bash", "zsh", "fish", "sh"}
+
+// envChange sets or, when unset is true, removes an environment variable.
+type envChange struct {
+	name  string
+	value string
+	unset bool
+}
+
+// addShellEnvFlag registers --shell-env on a command that changes the
+// environment of the calling shell.
+func addShellEnvFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&shellEnv, "shell-env", "", "Print environment changes as code for this shell (used by `gover init`)")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if shellEnv != "" && !validShell(shellEnv) {
+			return fmt.Errorf("unsupported shell %q for --shell-env; expected one of %s", shellEnv, strings.Join(supportedShells, ", "))
+		}
+		return nil
+	}
+	// The shell function evaluates stdout, so help must not end up there.
+	help := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if shellEnv != "" {
+			cmd.SetOut(os.Stderr)
+		}
+		help(cmd, args)
+	})
+}
+
+// messageWriter returns where human-readable output goes.
+func messageWriter() io.Writer {
+	if shellEnv != "" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func validShell(shell string) bool {
+	for _, s := range supportedShells {
+		if s == shell {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnv renders changes as code for shell.
+func formatEnv(shell string, changes []envChange) string {
+	var b strings.Builder
+	for _, c := range changes {
+		switch {
+		case shell == "fish" && c.unset:
+			fmt.Fprintf(&b, "set -e %s;\n", c.name)
+		case shell == "fish" && c.name == "PATH":
+			b.WriteString("set -gx PATH")
+			for _, dir := range filepath.SplitList(c.value) {
+				b.WriteString(" " + fishQuote(dir))
+			}
+			b.WriteString(";\n")
+		case shell == "fish":
+			fmt.Fprintf(&b, "set -gx %s %s;\n", c.name, fishQuote(c.value))
+		case c.unset:
+			fmt.Fprintf(&b, "unset %s\n", c.name)
+		default:
+			fmt.Fprintf(&b, "export %s=%s\n", c.name, shellQuote(c.value))
+		}
+	}
+	return b.String()
+}
+
+// fishQuote quotes s for fish, where only \ and ' are special inside single
+// quotes.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// activationEnv returns the changes that make goroot the Go of the current
+// shell. Directories gover put on PATH earlier are removed first so that
+// switching repeatedly does not grow PATH.
+func activationEnv(goroot string) []envChange {
+	changes := []envChange{
+		{name: "GOROOT", value: goroot},
+		{name: "PATH", value: filepath.Join(goroot, "bin") + string(os.PathListSeparator) + stripGoverPath(os.Getenv("PATH"))},
+	}
+	if gopath, ok := activationGOPATH(); ok {
+		changes = append(changes, envChange{name: "GOPATH", value: gopath})
+	}
+	if toolchain, ok := activationGOTOOLCHAIN(); ok {
+		changes = append(changes, envChange{name: "GOTOOLCHAIN", value: toolchain})
+	}
+	return changes
+}
+
+// activationGOTOOLCHAIN returns the GOTOOLCHAIN to export according to the
+// gotoolchain setting, and false when it should be left alone. The default,
+// local, stops the go command from downloading a different toolchain than
+// the one gover selected.
+func activationGOTOOLCHAIN() (string, bool) {
+	toolchain := settings().GOTOOLCHAIN
+	switch {
+	case toolchain == config.GOTOOLCHAINKeep:
+		return "", false
+	case toolchain == "":
+		return "local", true
+	case !config.ValidGOTOOLCHAIN(toolchain):
+		// Only a hand-edited file gets here; gover config set refuses it.
+		fmt.Fprintf(os.Stderr, "gover: ignoring invalid gotoolchain setting %q\n", toolchain)
+		return "local", true
+	}
+	return toolchain, true
+}
+
+// activationGOPATH returns the GOPATH to export according to the gopath
+// setting, and false when GOPATH should be left alone.
+func activationGOPATH() (string, bool) {
+	switch policy := settings().GOPATH; policy {
+	case config.GOPATHKeep:
+		return "", false
+	case "", config.GOPATHHome:
+		if os.Getenv("GOPATH") != "" {
+			return "", false
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		return filepath.Join(home, "go"), true
+	default:
+		return policy, true
+	}
+}
+
+// stripGoverPath removes the bin directories of gover-managed versions from
+// a PATH value. The shims directory is kept.
+func stripGoverPath(path string) string {
+	inst := newInstaller()
+	currentBin := filepath.Join(inst.CurrentLink(), "bin")
+	versionsDir := inst.VersionsDir() + string(os.PathSeparator)
+
+	var kept []string
+	for _, dir := range filepath.SplitList(path) {
+		if dir == currentBin || strings.HasPrefix(dir, versionsDir) {
+			continue
+		}
+		kept = append(kept, dir)
+	}
+	return strings.Join(kept, string(os.PathListSeparator))
+}
+
+// printActivation tells the shell, or the user, how to activate goroot.
+// Under --shell-env the environment changes are printed as code to
+// evaluate; otherwise the user is shown what to add to their profile.
+func printActivation(goroot string) {
+	if shellEnv != "" {
+		fmt.Print(formatEnv(shellEnv, activationEnv(goroot)))
+		return
+	}
+
+	shell := detectShell()
+	fmt.Println("👉 Add the following to your", shellProfile(shell), "if not already present,")
+	fmt.Println("   or set up the shell integration with `gover init " + shell + "`:")
+	fmt.Println()
+	bin := filepath.Join(goroot, "bin")
+	gopath := "$HOME/go"
+	policy := settings().GOPATH
+	if filepath.IsAbs(policy) {
+		gopath = policy
+	}
+	toolchain, setToolchain := activationGOTOOLCHAIN()
+	if shell == "fish" {
+		fmt.Printf("set -gx GOROOT \"%s\"\n", goroot)
+		fmt.Printf("set -gx PATH \"%s\" $PATH\n", bin)
+		if policy != config.GOPATHKeep {
+			fmt.Printf("set -gx GOPATH \"%s\"\n", gopath)
+		}
+		if setToolchain {
+			fmt.Printf("set -gx GOTOOLCHAIN \"%s\"\n", toolchain)
+		}
+		return
+	}
+	fmt.Printf("export GOROOT=\"%s\"\n", goroot)
+	fmt.Printf("export PATH=\"%s:$PATH\"\n", bin)
+	if policy != config.GOPATHKeep {
+		fmt.Printf("export GOPATH=\"%s\"\n", gopath)
+	}
+	if setToolchain {
+		fmt.Printf("export GOTOOLCHAIN=\"%s\"\n", toolchain)
+	}
+}
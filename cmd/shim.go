@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mullerhx/gover/internal/project"
+	"github.com/spf13/cobra"
+)
+
+// versionEnv selects the Go version for shims ahead of any project file.
+const versionEnv = "GOVER_VERSION"
+
+var errNoVersion = errors.New("no Go version selected; run `gover use <version>`")
+
+var shimCmd = &cobra.Command{
+	Use:                "shim <tool> [args...]",
+	Short:              "Run a tool from the Go version selected for the current directory",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Args:               cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, _, err := selectVersion()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gover:", err)
+			os.Exit(1)
+		}
+		os.Exit(runTool(version, args[0], args[1:]))
+	},
+}
+
+var rehashCmd = &cobra.Command{
+	Use:   "rehash",
+	Short: "Regenerate shims for the tools of all installed Go versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := rehash(); err != nil {
+			fmt.Println("Failed to write shims:", err)
+			os.Exit(1)
+		}
+		fmt.Println("✅ Shims written to", shimsDir())
+		fmt.Println("👉 Put it first on your PATH to select Go per directory:")
+		fmt.Println()
+		fmt.Printf("export PATH=\"%s:$PATH\"\n", shimsDir())
+	},
+}
+
+func shimsDir() string {
+	return resolvePaths().Shims
+}
+
+// selectVersion picks the installed version to run, in order of precedence:
+// the GOVER_VERSION environment variable, the nearest .go-version or
+// .tool-versions, the workspace or module (toolchain before go directive)
+// and finally the global default set by `gover use`. It never touches the network.
+func selectVersion() (string, string, error) {
+	if spec := os.Getenv(versionEnv); spec != "" {
+		version, ok := resolveInstalled(spec)
+		if !ok {
+			return "", "", fmt.Errorf("%s=%s is not installed; run `gover install %s`", versionEnv, spec, spec)
+		}
+		return version, versionEnv, nil
+	}
+
+	if dir, err := os.Getwd(); err == nil {
+		req, err := project.Detect(dir)
+		switch {
+		case err == nil && req.Exact:
+			version, ok := resolveInstalled(req.Version)
+			if !ok {
+				return "", "", fmt.Errorf("%s requested by %s is not installed; run `gover install %s`", req.Version, requestSource(req), req.Version)
+			}
+			return version, req.File, nil
+		case err == nil:
+			// The go directive states a minimum; any installed patch of
+			// that line satisfies it.
+			if version, ok := resolveInstalled("~" + req.Version); ok {
+				return version, req.File, nil
+			}
+		case !errors.Is(err, project.ErrNotFound):
+			return "", "", err
+		}
+	}
+
+	version, err := currentVersion()
+	if err != nil {
+		return "", "", err
+	}
+	return version, "global", nil
+}
+
+// currentVersion returns the version the current symlink points at. The
+// version is the first path component below the versions directory, so
+// links into the legacy versions/<version>/go layout work too; IsInstalled
+// migrates such installs and repoints the link.
+func currentVersion() (string, error) {
+	inst := newInstaller()
+	target, err := os.Readlink(inst.CurrentLink())
+	if err != nil {
+		return "", errNoVersion
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(inst.CurrentLink()), target)
+	}
+	version := filepath.Base(target)
+	if rel, err := filepath.Rel(inst.VersionsDir(), target); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		version, _, _ = strings.Cut(rel, string(filepath.Separator))
+	}
+	if !inst.IsInstalled(version) {
+		return "", fmt.Errorf("the current link points at %s, which is not installed; run `gover use <version>`", target)
+	}
+	return version, nil
+}
+
+// runTool runs tool from the bin directory of version and returns the
+// tool's exit code.
+func runTool(version, tool string, args []string) int {
+	root := newInstaller().Dir(version)
+	bin := filepath.Join(root, "bin", tool)
+	if _, err := os.Stat(bin); err != nil {
+		fmt.Fprintf(os.Stderr, "gover: %s is not provided by %s\n", tool, version)
+		return 127
+	}
+	return runWithGoroot(root, bin, args)
+}
+
+// runWithGoroot runs name with GOROOT set to goroot and its bin directory
+// first on PATH. Where possible name replaces this process; otherwise its
+// exit code is returned.
+func runWithGoroot(goroot, name string, args []string) int {
+	// Set PATH on this process too so name is looked up the way the tool
+	// will see it.
+	path := filepath.Join(goroot, "bin") + string(os.PathListSeparator) + stripGoverPath(os.Getenv("PATH"))
+	_ = os.Setenv("PATH", path)
+	_ = os.Setenv("GOROOT", goroot)
+	if toolchain, ok := activationGOTOOLCHAIN(); ok {
+		_ = os.Setenv("GOTOOLCHAIN", toolchain)
+	}
+	return execTool(name, args)
+}
+
+// rehash writes a shim for every tool found in the bin directory of any
+// installed version and removes shims for tools no longer provided.
+func rehash() error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir := shimsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	inst := newInstaller()
+	tools := map[string]bool{}
+	for _, v := range installedVersions() {
+		entries, err := os.ReadDir(filepath.Join(inst.Dir(v.String()), "bin"))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				tools[e.Name()] = true
+			}
+		}
+	}
+
+	existing, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if !tools[e.Name()] {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+
+	var names []string
+	for tool := range tools {
+		names = append(names, tool)
+	}
+	sort.Strings(names)
+	for _, tool := range names {
+		script := fmt.Sprintf("#!/bin/sh\nexec %s shim %s \"$@\"\n", shellQuote(self), shellQuote(tool))
+		if err := os.WriteFile(filepath.Join(dir, tool), []byte(script), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshShims regenerates the shims after versions were installed or
+// removed, provided the user has set them up with `gover rehash`.
+func refreshShims() {
+	if fileExists(shimsDir()) {
+		if err := rehash(); err != nil {
+			fmt.Println("Failed to update shims:", err)
+		}
+	}
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	RootCmd.AddCommand(shimCmd)
+	RootCmd.AddCommand(rehashCmd)
+}
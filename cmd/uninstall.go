@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
-	"os/user"
 	"path/filepath"
 )
 
@@ -16,15 +15,10 @@ var uninstallCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		version := args[0]
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Println("Failed to get user info:", err)
-			os.Exit(1)
-		}
-
-		installPath := filepath.Join(usr.HomeDir, ".gover", "versions", version)
+		inst := newInstaller()
+		installPath := inst.Dir(version)
 
-		currentPath, _ := filepath.EvalSymlinks(filepath.Join(usr.HomeDir, ".gover", "current"))
+		currentPath, _ := filepath.EvalSymlinks(inst.CurrentLink())
 
 		if currentPath == installPath && !force {
 			fmt.Printf("⚠️  %s is currently in use. Use --force to uninstall it anyway.\n", version)
@@ -36,12 +30,12 @@ var uninstallCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		err = os.RemoveAll(installPath)
-		if err != nil {
+		if err := os.RemoveAll(installPath); err != nil {
 			fmt.Println("Failed to uninstall:", err)
 			os.Exit(1)
 		}
 
+		refreshShims()
 		fmt.Printf("✅ Uninstalled %s\n", version)
 	},
 }
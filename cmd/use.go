@@ -1,108 +1,102 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
-	"runtime"
-	"sort"
 	"strings"
+	"time"
 
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/mullerhx/gover/internal/project"
+	"github.com/mullerhx/gover/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var autoUse = false
+var useInstall bool
+
+// resolution overrides the resolution setting for detect and use --auto.
+var resolution string
 
 var useCmd = &cobra.Command{
 	Use:   "use [version]",
 	Short: "Switch to a specific Go version",
-	Args:  cobra.ExactArgs(1),
+	Long: `Switch to a specific Go version.
+
+The version may be exact or a constraint such as 1.22, ~1.21 or stable; the
+newest installed version satisfying it is preferred over releases that are
+not installed yet.
+
+With --install, or the auto_install setting, a version that is not installed
+yet is downloaded, verified and installed first, so that a fresh checkout
+only needs:
+
+  gover use --auto --install`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		out := messageWriter()
 
 		if autoUse {
-			version, err := detectGoModVersion()
+			version, req, err := detectVersion()
 			if err != nil {
-				fmt.Println("Auto detection failed:", err)
+				fmt.Fprintln(out, "Auto detection failed:", err)
 				os.Exit(1)
 			}
+			fmt.Fprintf(out, "Detected %s from %s\n", version, requestSource(req))
 			args = []string{version}
 		}
 
 		if len(args) < 1 {
-			fmt.Println("Usage: gover use <version> or --auto")
-			os.Exit(1)
-		}
-		version := args[0]
-		if !strings.HasPrefix(version, "go") {
-			version = "go" + version
-		}
-
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Println("Failed to get current user:", err)
-			os.Exit(1)
-		}
-
-		installPath := filepath.Join(usr.HomeDir, ".gover", "versions", version, "go")
-		if _, err := os.Stat(installPath); os.IsNotExist(err) {
-			fmt.Printf("Version %s not installed. Run `gover install %s` first.\n", version, version)
-			os.Exit(1)
-		}
-
-		currentLink := filepath.Join(usr.HomeDir, ".gover", "current")
-		_ = os.Remove(currentLink)
-		if err := os.Symlink(installPath, currentLink); err != nil {
-			fmt.Println("Failed to create symlink:", err)
+			fmt.Fprintln(out, "Usage: gover use <version> or --auto")
 			os.Exit(1)
 		}
-		currentBinLink := filepath.Join(usr.HomeDir, ".gover", "current", "bin")
-		files, err := os.ReadDir(currentBinLink)
-		if err != nil {
-			fmt.Println("Failed to read bin directory:", err)
-		} else {
-			for _, file := range files {
-				if !file.IsDir() {
-					filePath := filepath.Join(currentBinLink, file.Name())
-					err := os.Chmod(filePath, 0755)
-					if err != nil {
-						fmt.Printf("Failed to chmod %s: %v\n", filePath, err)
-					}
-				}
+		version, ok := resolveInstalled(args[0])
+		if !ok {
+			var err error
+			version, err = resolveVersion(args[0])
+			if err != nil {
+				fmt.Fprintln(out, "Failed to resolve version:", err)
+				os.Exit(1)
 			}
 		}
 
-		toolsLink := filepath.Join(usr.HomeDir, ".gover", "current", "pkg", "tool", runtime.GOOS+"_"+runtime.GOARCH)
-		files, err = os.ReadDir(toolsLink)
-		if err != nil {
-			fmt.Println("Failed to read ", toolsLink, " directory:", err)
-		} else {
-			for _, file := range files {
-				if !file.IsDir() {
-					filePath := filepath.Join(toolsLink, file.Name())
-					err := os.Chmod(filePath, 0755)
-					if err != nil {
-						fmt.Printf("Failed to chmod %s: %v\n", filePath, err)
-					}
-				}
+		if inst := newInstaller(); !inst.IsInstalled(version) {
+			if !useInstall && !settings().AutoInstall {
+				fmt.Fprintf(out, "Version %s not installed. Run `gover install %s` first or pass --install.\n", version, version)
+				os.Exit(1)
+			}
+			fmt.Fprintf(out, "Version %s not installed. Installing...\n", version)
+			archive, err := findArchive(version)
+			if err != nil {
+				fmt.Fprintln(out, "Failed to look up release:", err)
+				os.Exit(1)
+			}
+			inst.Progress = out
+			if err := inst.Install(archive); err != nil {
+				fmt.Fprintln(out, "Installation failed:", err)
+				os.Exit(1)
 			}
+			refreshShims()
 		}
 
-		shell := detectShell()
-		profilePath := shellProfile(shell)
-
-		fmt.Println("✅ Go version", version, "is now active via ~/.gover/current")
-		fmt.Println("👉 Add the following to your", profilePath, "if not already present:\n")
+		if err := switchVersion(version); err != nil {
+			fmt.Fprintln(out, "Failed to switch version:", err)
+			os.Exit(1)
+		}
 
-		fmt.Println("export GOROOT=\"$HOME/.gover/current\"")
-		fmt.Println("export PATH=\"$HOME/.gover/current/bin:$PATH\"")
-		fmt.Println("export GOPATH=\"$HOME/go\"")
+		fmt.Fprintln(out, "✅ Go version", version, "is now active via", newInstaller().CurrentLink())
+		printActivation(newInstaller().CurrentLink())
 	},
 }
 
 func init() {
-	useCmd.Flags().BoolVar(&autoUse, "auto", false, "Automatically detect version from go.mod")
+	useCmd.Flags().BoolVar(&autoUse, "auto", false, "Automatically detect version from .go-version, .tool-versions, go.work or go.mod")
+	useCmd.Flags().BoolVar(&useInstall, "install", false, "Install the version first if it is missing")
+	useCmd.Flags().DurationVar(&downloadTimeout, "timeout", 30*time.Minute, "Overall download timeout including retries (0 for none)")
+	addResolutionFlag(useCmd)
+	addShellEnvFlag(useCmd)
 	RootCmd.AddCommand(useCmd)
 }
 
@@ -134,68 +128,111 @@ func shellProfile(shell string) string {
 	}
 }
 
-func detectGoModVersion() (string, error) {
+// detectVersion resolves the version requested by the nearest .go-version,
+// .tool-versions, go.work or go.mod to a concrete release.
+func detectVersion() (string, project.Request, error) {
 	dir, err := os.Getwd()
 	if err != nil {
-		return "", err
+		return "", project.Request{}, err
 	}
 
-	for {
-		goModPath := filepath.Join(dir, "go.mod")
-		if fileExists(goModPath) {
-			data, err := os.ReadFile(goModPath)
-			if err != nil {
-				return "", err
-			}
-
-			lines := strings.Split(string(data), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if strings.HasPrefix(line, "go ") {
-					version := strings.TrimPrefix(line, "go ")
-					version = strings.TrimSpace(version)
-					return resolveLatestPatch("go" + version)
-				}
-			}
-			break
-		}
+	req, err := project.Detect(dir)
+	if err != nil {
+		return "", req, err
+	}
 
-		// move one directory up
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break // reached root
+	if !req.Exact {
+		// The go directive states a minimum.
+		policy, err := resolutionPolicy()
+		if err != nil {
+			return "", req, err
 		}
-		dir = parent
+		version, err := resolveMinimum(req.Version, policy)
+		return version, req, err
+	}
+	if version, ok := resolveInstalled(req.Version); ok {
+		return version, req, nil
 	}
+	version, err := resolveVersion(req.Version)
+	return version, req, err
+}
 
-	return "", fmt.Errorf("no go.mod found")
+// requestSource describes where req came from, naming the directive when it
+// was read from go.mod or go.work.
+func requestSource(req project.Request) string {
+	if req.Directive == "" {
+		return req.File
+	}
+	return fmt.Sprintf("the %s directive in %s", req.Directive, req.File)
 }
 
-func resolveLatestPatch(prefix string) (string, error) {
-	usr, _ := user.Current()
-	cachePath := filepath.Join(usr.HomeDir, ".gover", "releases.json")
+func addResolutionFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&resolution, "resolution", "", "How to resolve a go directive: "+strings.Join(config.ResolutionPolicies, ", ")+" (default from config, else latest-patch)")
+}
 
-	var versions []GoVersion
-	file, err := os.Open(cachePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read release cache: %w", err)
+// resolutionPolicy returns the --resolution flag, the resolution setting or
+// latest-patch.
+func resolutionPolicy() (string, error) {
+	policy := resolution
+	if policy == "" {
+		policy = settings().Resolution
 	}
-	defer file.Close()
-	if err := json.NewDecoder(file).Decode(&versions); err != nil {
-		return "", err
+	if policy == "" {
+		return config.ResolveLatestPatch, nil
+	}
+	if !config.ValidResolution(policy) {
+		return "", fmt.Errorf("unknown resolution policy %q; expected one of %s", policy, strings.Join(config.ResolutionPolicies, ", "))
 	}
+	return policy, nil
+}
 
-	var matches []string
-	for _, v := range versions {
-		if strings.HasPrefix(v.Version, prefix) && v.Stable {
-			matches = append(matches, v.Version)
+// resolveMinimum resolves the minimum version a go directive states
+// according to policy, choosing from the installed versions or the release
+// index as the policy requires.
+func resolveMinimum(minimum, policy string) (string, error) {
+	var available []version.Version
+	switch policy {
+	case config.ResolveExact:
+	case config.ResolveLatestInstalled:
+		available = installedVersions()
+	default:
+		versions, err := loadReleases(false)
+		if err != nil {
+			return "", err
 		}
+		available = releaseVersions(versions)
 	}
+	return selectMinimum(minimum, policy, available)
+}
 
-	if len(matches) == 0 {
-		return "", fmt.Errorf("no matching versions for prefix %s", prefix)
+// selectMinimum picks the version policy resolves minimum to from available.
+// Lines are matched by version rather than by name, so go 1.2 never selects
+// go1.21 or go1.22.
+func selectMinimum(minimum, policy string, available []version.Version) (string, error) {
+	v, err := version.Parse(minimum)
+	if err != nil {
+		return "", err
 	}
 
-	sort.Strings(matches)
-	return matches[len(matches)-1], nil
+	var expr string
+	switch policy {
+	case config.ResolveExact:
+		return v.Release().String(), nil
+	case config.ResolveLatestInstalled, config.ResolveLatestMinor:
+		expr = ">=" + minimum
+	default:
+		expr = "~" + minimum
+	}
+	c, err := version.ParseConstraint(expr)
+	if err != nil {
+		return "", err
+	}
+	latest, ok := c.Select(available)
+	switch {
+	case !ok && policy == config.ResolveLatestInstalled:
+		return "", fmt.Errorf("no installed version satisfies go %s; run `gover install %s`", minimum, minimum)
+	case !ok:
+		return "", fmt.Errorf("no Go release matches %s", expr)
+	}
+	return latest.String(), nil
 }
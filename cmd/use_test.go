@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mullerhx/gover/internal/config"
+	"github.com/mullerhx/gover/internal/version"
+)
+
+func parseVersions(t *testing.T, names ...string) []version.Version {
+	t.Helper()
+	versions := make([]version.Version, len(names))
+	for i, name := range names {
+		v, err := version.Parse(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions[i] = v
+	}
+	return versions
+}
+
+func TestSelectMinimum(t *testing.T) {
+	index := parseVersions(t,
+		"go1.2", "go1.2.2",
+		"go1.20rc1", "go1.20", "go1.20.14",
+		"go1.21rc2", "go1.21.0", "go1.21.13",
+		"go1.22.0", "go1.22.5",
+	)
+	installed := parseVersions(t, "go1.20.14", "go1.21.3")
+
+	tests := []struct {
+		minimum   string
+		policy    string
+		available []version.Version
+		want      string // "" when resolution fails
+	}{
+		{"1.2", config.ResolveLatestPatch, index, "go1.2.2"},
+		{"1.20", config.ResolveLatestPatch, index, "go1.20.14"},
+		{"1.21", config.ResolveLatestPatch, index, "go1.21.13"},
+		{"1.21.3", config.ResolveLatestPatch, index, "go1.21.13"},
+		{"1.23", config.ResolveLatestPatch, index, ""},
+		{"1.20", config.ResolveExact, nil, "go1.20"},
+		{"1.21", config.ResolveExact, nil, "go1.21.0"},
+		{"1.21.3", config.ResolveExact, nil, "go1.21.3"},
+		{"1.20", config.ResolveLatestMinor, index, "go1.22.5"},
+		{"1.23", config.ResolveLatestMinor, index, ""},
+		{"1.20", config.ResolveLatestInstalled, installed, "go1.21.3"},
+		{"1.21.4", config.ResolveLatestInstalled, installed, ""},
+		{"1.21", config.ResolveLatestInstalled, nil, ""},
+	}
+	for _, tt := range tests {
+		got, err := selectMinimum(tt.minimum, tt.policy, tt.available)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("go %s with %s selected %s, want an error", tt.minimum, tt.policy, got)
+		case tt.want != "" && err != nil:
+			t.Errorf("go %s with %s: %v", tt.minimum, tt.policy, err)
+		case got != tt.want:
+			t.Errorf("go %s with %s selected %s, want %s", tt.minimum, tt.policy, got, tt.want)
+		}
+	}
+}
+
+// The go1.2 line must not pick up go1.21.x or go1.22.x, which share its
+// name as a prefix.
+func TestSelectMinimumMatchesLinesByVersion(t *testing.T) {
+	index := parseVersions(t, "go1.21.0", "go1.21.13", "go1.22.5")
+	if got, err := selectMinimum("1.2", config.ResolveLatestPatch, index); err == nil {
+		t.Errorf("go 1.2 selected %s from an index without go1.2.x", got)
+	}
+}
@@ -2,7 +2,11 @@ module github.com/mullerhx/gover
 
 go 1.24.2
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/spf13/cobra v1.9.1
+	golang.org/x/mod v0.33.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,154 @@
+// Package cache stores downloaded release archives so they can be reused
+// across installs without touching the network.
+//
+// Archives are content addressed: each one lives at <dir>/<sha256>/<filename>,
+// so a cache directory can be shared between machines or mounted into
+// containers without risk of picking up the wrong file.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PartialSuffix is appended to archives that are still being downloaded. It
+// matches download.PartialSuffix.
+const PartialSuffix = ".partial"
+
+// Cache is an archive cache rooted at Dir.
+type Cache struct {
+	Dir string
+}
+
+// Entry describes a file in the cache.
+type Entry struct {
+	Filename string
+	Sha256   string
+	Path     string
+	Size     int64
+	ModTime  time.Time
+	// Partial is set for downloads that have not completed.
+	Partial bool
+}
+
+// New returns a Cache rooted at dir.
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// Path returns where the archive filename with the given digest is stored.
+func (c *Cache) Path(filename, sha256 string) string {
+	return filepath.Join(c.Dir, strings.ToLower(sha256), filename)
+}
+
+// Lookup returns the path of a cached archive if it is present and its
+// content still matches sha256. Corrupt entries are removed.
+func (c *Cache) Lookup(filename, sha256 string) (string, bool) {
+	path := c.Path(filename, sha256)
+	sum, err := hashFile(path)
+	if err != nil {
+		return "", false
+	}
+	if !strings.EqualFold(sum, sha256) {
+		_ = os.Remove(path)
+		return "", false
+	}
+	return path, true
+}
+
+// Entries lists every archive in the cache, complete or partial, sorted by
+// filename.
+func (c *Cache) Entries() ([]Entry, error) {
+	dirs, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(c.Dir, dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			name := file.Name()
+			entries = append(entries, Entry{
+				Filename: strings.TrimSuffix(name, PartialSuffix),
+				Sha256:   dir.Name(),
+				Path:     filepath.Join(c.Dir, dir.Name(), name),
+				Size:     info.Size(),
+				ModTime:  info.ModTime(),
+				Partial:  strings.HasSuffix(name, PartialSuffix),
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Filename < entries[j].Filename
+	})
+	return entries, nil
+}
+
+// Size returns the number of bytes used by the cache.
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	return total, nil
+}
+
+// Clean removes the entries for which keep returns false and reports how
+// many bytes were freed. A nil keep removes everything.
+func (c *Cache) Clean(keep func(Entry) bool) (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	var freed int64
+	for _, e := range entries {
+		if keep != nil && keep(e) {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil {
+			return freed, err
+		}
+		freed += e.Size
+		// Drop the digest directory once it is empty.
+		_ = os.Remove(filepath.Dir(e.Path))
+	}
+	return freed, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
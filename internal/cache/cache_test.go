@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// put writes content to the cache at the path for filename and sha256.
+func put(t *testing.T, c *Cache, filename, sha256, content string) string {
+	t.Helper()
+	path := c.Path(filename, sha256)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	c := New(t.TempDir())
+	sum := digest([]byte("archive"))
+	path := put(t, c, "go1.22.3.linux-amd64.tar.gz", sum, "archive")
+
+	got, ok := c.Lookup("go1.22.3.linux-amd64.tar.gz", sum)
+	if !ok || got != path {
+		t.Errorf("Lookup = %q, %v; want %q, true", got, ok, path)
+	}
+	if _, ok := c.Lookup("go1.22.4.linux-amd64.tar.gz", sum); ok {
+		t.Error("Lookup found an archive that was never stored")
+	}
+}
+
+func TestLookupRemovesCorruptEntry(t *testing.T) {
+	c := New(t.TempDir())
+	sum := digest([]byte("archive"))
+	path := put(t, c, "go1.22.3.linux-amd64.tar.gz", sum, "truncated")
+
+	if _, ok := c.Lookup("go1.22.3.linux-amd64.tar.gz", sum); ok {
+		t.Error("Lookup returned an archive whose content does not match its digest")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt entry %s was not removed (%v)", path, err)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	c := New(t.TempDir())
+	put(t, c, "go1.22.3.linux-amd64.tar.gz", "aaaa", "complete")
+	put(t, c, "go1.21.0.linux-amd64.tar.gz"+PartialSuffix, "bbbb", "part")
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Entries = %+v, want 2 entries", entries)
+	}
+	partial, complete := entries[0], entries[1]
+	if partial.Filename != "go1.21.0.linux-amd64.tar.gz" || !partial.Partial || partial.Sha256 != "bbbb" || partial.Size != 4 {
+		t.Errorf("partial entry = %+v", partial)
+	}
+	if complete.Filename != "go1.22.3.linux-amd64.tar.gz" || complete.Partial || complete.Sha256 != "aaaa" || complete.Size != 8 {
+		t.Errorf("complete entry = %+v", complete)
+	}
+}
+
+func TestEntriesMissingDir(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "missing"))
+	entries, err := c.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Entries = %v, %v; want no entries", entries, err)
+	}
+}
+
+func TestCleanPartials(t *testing.T) {
+	c := New(t.TempDir())
+	complete := put(t, c, "go1.22.3.linux-amd64.tar.gz", "aaaa", "complete")
+	put(t, c, "go1.22.4.linux-amd64.tar.gz"+PartialSuffix, "aaaa", "part")
+	orphan := put(t, c, "go1.21.0.linux-amd64.tar.gz"+PartialSuffix, "bbbb", "partial")
+
+	freed, err := c.Clean(func(e Entry) bool { return !e.Partial })
+	if err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	if freed != int64(len("part")+len("partial")) {
+		t.Errorf("Clean freed %d bytes, want %d", freed, len("part")+len("partial"))
+	}
+	if _, err := os.Stat(complete); err != nil {
+		t.Errorf("complete archive was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(orphan)); !os.IsNotExist(err) {
+		t.Errorf("emptied digest directory %s was kept (%v)", filepath.Dir(orphan), err)
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != complete {
+		t.Errorf("Entries after Clean = %+v, want only %s", entries, complete)
+	}
+}
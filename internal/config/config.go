@@ -0,0 +1,335 @@
+// Package config reads and writes the gover configuration file.
+//
+// The file lives at $GOVER_CONFIG if set, otherwise at
+// $XDG_CONFIG_HOME/gover/config.toml, falling back to
+// ~/.config/gover/config.toml. Every setting is optional; an empty value
+// means the built-in default.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mullerhx/gover/internal/version"
+)
+
+// PathEnv overrides the location of the configuration file.
+const PathEnv = "GOVER_CONFIG"
+
+// GOPATH policies. Any other value of Config.GOPATH is a directory that is
+// always exported as GOPATH.
+const (
+	// GOPATHHome exports $HOME/go when GOPATH is not set already.
+	GOPATHHome = "home"
+	// GOPATHKeep never touches GOPATH.
+	GOPATHKeep = "keep"
+)
+
+// GOTOOLCHAINKeep leaves GOTOOLCHAIN alone on activation. Any other value of
+// Config.GOTOOLCHAIN is exported, "local" when unset.
+const GOTOOLCHAINKeep = "keep"
+
+// Resolution policies for the minimum version a go directive states.
+const (
+	// ResolveExact uses the minimum itself, e.g. go1.21.0 for "go 1.21".
+	ResolveExact = "exact"
+	// ResolveLatestPatch uses the newest release of the minimum's line.
+	ResolveLatestPatch = "latest-patch"
+	// ResolveLatestInstalled uses the newest installed version from the
+	// minimum on.
+	ResolveLatestInstalled = "latest-installed"
+	// ResolveLatestMinor uses the newest release from the minimum on.
+	ResolveLatestMinor = "latest-minor"
+)
+
+// ResolutionPolicies lists the valid resolution policies.
+var ResolutionPolicies = []string{ResolveExact, ResolveLatestPatch, ResolveLatestInstalled, ResolveLatestMinor}
+
+// Config holds the settings from the configuration file.
+type Config struct {
+	Root        string   `toml:"root,omitempty"`
+	Mirrors     []string `toml:"mirrors,omitempty"`
+	IndexURLs   []string `toml:"index_urls,omitempty"`
+	GOPATH      string   `toml:"gopath,omitempty"`
+	AutoInstall bool     `toml:"auto_install,omitempty"`
+	IndexTTL    string   `toml:"index_ttl,omitempty"`
+	Output      string   `toml:"output,omitempty"`
+	ListLimit   *int     `toml:"list_limit,omitempty"`
+	Resolution  string   `toml:"resolution,omitempty"`
+	GOTOOLCHAIN string   `toml:"gotoolchain,omitempty"`
+}
+
+// Setting describes a key that `gover config` can read and write.
+type Setting struct {
+	Key         string
+	Default     string
+	Description string
+	get         func(*Config) string
+	set         func(*Config, string) error
+}
+
+// Settings lists every key in the order `gover config list` shows them.
+var Settings = []Setting{
+	{
+		Key:         "root",
+		Default:     "~/.gover if it exists, else the XDG base directories",
+		Description: "Single directory for installed versions, shims, archives and the release index",
+		get:         func(c *Config) string { return c.Root },
+		set: func(c *Config, v string) error {
+			c.Root = v
+			return nil
+		},
+	},
+	{
+		Key:         "mirrors",
+		Default:     "https://go.dev/dl/",
+		Description: "Comma-separated base URLs to download release archives from, tried in order",
+		get:         func(c *Config) string { return strings.Join(c.Mirrors, ",") },
+		set: func(c *Config, v string) error {
+			c.Mirrors = SplitList(v)
+			return nil
+		},
+	},
+	{
+		Key:         "index_urls",
+		Default:     "each mirror with ?mode=json&include=all",
+		Description: "Comma-separated URLs of the JSON release index, tried in order",
+		get:         func(c *Config) string { return strings.Join(c.IndexURLs, ",") },
+		set: func(c *Config, v string) error {
+			c.IndexURLs = SplitList(v)
+			return nil
+		},
+	},
+	{
+		Key:         "gopath",
+		Default:     GOPATHHome,
+		Description: `GOPATH to export on activation: "home" for $HOME/go when unset, "keep" to leave it alone, or a directory`,
+		get:         func(c *Config) string { return c.GOPATH },
+		set: func(c *Config, v string) error {
+			if v != "" && v != GOPATHHome && v != GOPATHKeep && !filepath.IsAbs(v) {
+				return fmt.Errorf("gopath must be %q, %q or an absolute directory", GOPATHHome, GOPATHKeep)
+			}
+			c.GOPATH = v
+			return nil
+		},
+	},
+	{
+		Key:         "auto_install",
+		Default:     "false",
+		Description: "Install missing versions instead of failing",
+		get: func(c *Config) string {
+			if !c.AutoInstall {
+				return ""
+			}
+			return "true"
+		},
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.AutoInstall = false
+				return nil
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("auto_install must be true or false")
+			}
+			c.AutoInstall = b
+			return nil
+		},
+	},
+	{
+		Key:         "index_ttl",
+		Default:     "24h",
+		Description: "How long the cached release index is used before it is refreshed",
+		get:         func(c *Config) string { return c.IndexTTL },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if _, err := time.ParseDuration(v); err != nil {
+					return fmt.Errorf("index_ttl must be a duration such as 24h: %w", err)
+				}
+			}
+			c.IndexTTL = v
+			return nil
+		},
+	},
+	{
+		Key:         "output",
+		Default:     "text",
+		Description: "Default for --output: text or json",
+		get:         func(c *Config) string { return c.Output },
+		set: func(c *Config, v string) error {
+			if v != "" && v != "text" && v != "json" {
+				return fmt.Errorf("output must be text or json")
+			}
+			c.Output = v
+			return nil
+		},
+	},
+	{
+		Key:         "list_limit",
+		Default:     "20",
+		Description: "Versions shown per minor release by `gover list` (0 for all)",
+		get: func(c *Config) string {
+			if c.ListLimit == nil {
+				return ""
+			}
+			return strconv.Itoa(*c.ListLimit)
+		},
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.ListLimit = nil
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("list_limit must be a non-negative number")
+			}
+			c.ListLimit = &n
+			return nil
+		},
+	},
+	{
+		Key:         "resolution",
+		Default:     ResolveLatestPatch,
+		Description: "How detect and use --auto resolve a go directive: " + strings.Join(ResolutionPolicies, ", "),
+		get:         func(c *Config) string { return c.Resolution },
+		set: func(c *Config, v string) error {
+			if v != "" && !ValidResolution(v) {
+				return fmt.Errorf("resolution must be one of %s", strings.Join(ResolutionPolicies, ", "))
+			}
+			c.Resolution = v
+			return nil
+		},
+	},
+	{
+		Key:         "gotoolchain",
+		Default:     "local",
+		Description: `GOTOOLCHAIN to export on activation so that go does not fetch other toolchains, or "keep" to leave it alone`,
+		get:         func(c *Config) string { return c.GOTOOLCHAIN },
+		set: func(c *Config, v string) error {
+			if v != "" && !ValidGOTOOLCHAIN(v) {
+				return fmt.Errorf(`gotoolchain must be "keep", "local", "auto", "path", a Go version such as go1.22.3, or a Go version followed by +auto or +path`)
+			}
+			c.GOTOOLCHAIN = v
+			return nil
+		},
+	},
+}
+
+// ValidGOTOOLCHAIN reports whether value is GOTOOLCHAINKeep or a
+// GOTOOLCHAIN setting the go command accepts.
+func ValidGOTOOLCHAIN(value string) bool {
+	switch value {
+	case GOTOOLCHAINKeep, "local", "auto", "path":
+		return true
+	}
+	name, _ := strings.CutSuffix(value, "+auto")
+	name, _ = strings.CutSuffix(name, "+path")
+	if !strings.HasPrefix(name, "go") || !strings.Contains(name, ".") {
+		return false
+	}
+	_, err := version.Parse(name)
+	return err == nil
+}
+
+// ValidResolution reports whether policy is one of ResolutionPolicies.
+func ValidResolution(policy string) bool {
+	for _, p := range ResolutionPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the setting called key.
+func Lookup(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Get returns the value of s in c, or "" when it is not set.
+func (s Setting) Get(c *Config) string {
+	return s.get(c)
+}
+
+// Set validates value and stores it in c. An empty value resets s to its
+// default.
+func (s Setting) Set(c *Config, value string) error {
+	return s.set(c, strings.TrimSpace(value))
+}
+
+// Path returns the location of the configuration file.
+func Path() (string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gover", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gover", "config.toml"), nil
+}
+
+// Load reads the configuration file at path. A missing file yields an empty
+// Config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	_, err := toml.DecodeFile(path, c)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes c to path, replacing the file atomically.
+func (c *Config) Save(path string) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ListLimitOr returns the configured list limit, or def when it is unset.
+// Zero means no limit.
+func (c *Config) ListLimitOr(def int) int {
+	if c.ListLimit == nil {
+		return def
+	}
+	return *c.ListLimit
+}
+
+// SplitList splits a comma-separated list, dropping empty entries.
+func SplitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,275 @@
+// Package download fetches release archives over HTTP, resuming interrupted
+// transfers and retrying transient failures.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Defaults used by New.
+const (
+	DefaultRetries = 5
+	DefaultBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// PartialSuffix is appended to the destination while a download is in
+// progress.
+const PartialSuffix = ".partial"
+
+// Downloader writes a download to <dest>.partial and renames it to dest once
+// complete and verified. A .partial file left by an earlier attempt is
+// resumed with an HTTP Range request.
+type Downloader struct {
+	// Client performs the requests; http.DefaultClient when nil.
+	Client *http.Client
+	// Retries is the number of additional attempts after a transient
+	// failure.
+	Retries int
+	// Backoff is the delay before the first retry; it doubles on every
+	// further attempt.
+	Backoff time.Duration
+	// Timeout bounds the whole download including retries. Zero means no
+	// limit.
+	Timeout time.Duration
+	// Progress receives progress output when non-nil.
+	Progress io.Writer
+}
+
+// New returns a Downloader with the default retry policy.
+func New() *Downloader {
+	return &Downloader{Retries: DefaultRetries, Backoff: DefaultBackoff}
+}
+
+// Request describes a file to download.
+type Request struct {
+	URL      string
+	Filename string
+	// Sha256 is the expected hex-encoded digest of the complete file.
+	Sha256 string
+	// Size is the expected length in bytes, or zero when unknown.
+	Size int64
+}
+
+// ChecksumError reports a download whose content does not match the
+// expected digest.
+type ChecksumError struct {
+	Filename string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.Filename, e.Expected, e.Actual)
+}
+
+// permanentError marks failures that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Fetch downloads req into dest, which only appears once its content has
+// been verified.
+func (d *Downloader) Fetch(ctx context.Context, req Request, dest string) error {
+	return d.FetchAny(ctx, []string{req.URL}, req, dest)
+}
+
+// FetchAny downloads req from the first of urls that serves it, ignoring
+// req.URL. Every round tries each URL once, moving on to the next after a
+// connection failure or error response; only once all of them have failed
+// does it back off and retry those whose failure may be transient. Timeout
+// bounds the whole download across all URLs.
+func (d *Downloader) FetchAny(ctx context.Context, urls []string, req Request, dest string) error {
+	if len(urls) == 0 {
+		return fmt.Errorf("no URL to download %s from", req.Filename)
+	}
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	partial := dest + PartialSuffix
+	backoff := d.Backoff
+	pending := urls
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry []string
+		for n, url := range pending {
+			if d.Progress != nil {
+				if n > 0 {
+					fmt.Fprintf(d.Progress, "\nDownload failed (%v), trying next mirror\n", err)
+				}
+				if attempt == 0 || len(pending) > 1 {
+					fmt.Fprintln(d.Progress, "Downloading:", url)
+				}
+			}
+			r := req
+			r.URL = url
+			err = d.attempt(ctx, r, partial)
+			if err == nil {
+				return os.Rename(partial, dest)
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("download of %s timed out: %w", req.Filename, err)
+			}
+			var perm *permanentError
+			if !errors.As(err, &perm) {
+				retry = append(retry, url)
+			}
+		}
+		if len(retry) == 0 || attempt >= d.Retries {
+			return err
+		}
+
+		if d.Progress != nil {
+			fmt.Fprintf(d.Progress, "\nDownload interrupted (%v), retrying in %s...\n", err, backoff)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("download of %s timed out: %w", req.Filename, err)
+		}
+		backoff = min(backoff*2, maxBackoff)
+		pending = retry
+	}
+}
+
+// attempt makes one request, appending to partial from wherever the
+// previous attempt stopped, and verifies the file once it is complete.
+func (d *Downloader) attempt(ctx context.Context, req Request, partial string) error {
+	out, err := os.OpenFile(partial, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return &permanentError{err}
+	}
+	defer out.Close()
+
+	// Seed the digest with what was already downloaded so the archive is
+	// still hashed in a single pass.
+	h := sha256.New()
+	offset, err := io.Copy(h, out)
+	if err != nil {
+		return &permanentError{err}
+	}
+
+	if req.Size > 0 && offset >= req.Size {
+		if offset == req.Size {
+			return d.verify(req, partial, h.Sum(nil))
+		}
+		offset = 0
+	}
+	if offset == 0 {
+		h.Reset()
+		if err := out.Truncate(0); err != nil {
+			return &permanentError{err}
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return &permanentError{err}
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	if offset > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if total >= 0 {
+			total += offset
+		}
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range; start over.
+		offset = 0
+		h.Reset()
+		if err := out.Truncate(0); err != nil {
+			return &permanentError{err}
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return &permanentError{err}
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file no longer matches what the server has.
+		_ = out.Truncate(0)
+		return fmt.Errorf("download failed with status: %s", resp.Status)
+	case transientStatus(resp.StatusCode):
+		return fmt.Errorf("download failed with status: %s", resp.Status)
+	default:
+		return &permanentError{fmt.Errorf("download failed with status: %s", resp.Status)}
+	}
+
+	var body io.Reader = resp.Body
+	if d.Progress != nil {
+		if offset > 0 {
+			fmt.Fprintf(d.Progress, "Resuming %s at %d bytes\n", req.Filename, offset)
+		}
+		body = &progressReader{Reader: resp.Body, out: d.Progress, read: offset, total: total}
+	}
+	if _, err := io.Copy(io.MultiWriter(out, h), body); err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if d.Progress != nil {
+		fmt.Fprintln(d.Progress)
+	}
+	if err := out.Sync(); err != nil {
+		return &permanentError{err}
+	}
+	return d.verify(req, partial, h.Sum(nil))
+}
+
+func (d *Downloader) verify(req Request, partial string, sum []byte) error {
+	actual := hex.EncodeToString(sum)
+	if !strings.EqualFold(actual, req.Sha256) {
+		_ = os.Remove(partial)
+		return &permanentError{&ChecksumError{Filename: req.Filename, Expected: req.Sha256, Actual: actual}}
+	}
+	return nil
+}
+
+func transientStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+type progressReader struct {
+	Reader io.Reader
+	out    io.Writer
+	total  int64
+	read   int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.Reader.Read(p)
+	pr.read += int64(n)
+	if pr.total > 0 {
+		fmt.Fprintf(pr.out, "\rProgress: %.2f%%", float64(pr.read)/float64(pr.total)*100)
+	}
+	return n, err
+}
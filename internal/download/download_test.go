@@ -0,0 +1,311 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var content = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// server serves content through handle and counts the requests it gets and
+// how many of them asked for a range.
+type server struct {
+	*httptest.Server
+	requests atomic.Int32
+	ranges   atomic.Int32
+}
+
+func newServer(t *testing.T, handle func(n int32, w http.ResponseWriter, r *http.Request)) *server {
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.requests.Add(1)
+		if r.Header.Get("Range") != "" {
+			s.ranges.Add(1)
+		}
+		handle(n, w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// serveContent answers range requests the way a well-behaved server does.
+func serveContent(_ int32, w http.ResponseWriter, r *http.Request) {
+	http.ServeContent(w, r, "go.tar.gz", time.Time{}, bytes.NewReader(content))
+}
+
+func fetch(t *testing.T, s *server, sum string, prepare func(dest string)) (string, error) {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "go.tar.gz")
+	if prepare != nil {
+		prepare(dest)
+	}
+	d := New()
+	d.Backoff = time.Millisecond
+	err := d.Fetch(context.Background(), Request{
+		URL:      s.URL + "/go.tar.gz",
+		Filename: "go.tar.gz",
+		Sha256:   sum,
+		Size:     int64(len(content)),
+	}, dest)
+	return dest, err
+}
+
+func checkDownloaded(t *testing.T, dest string) {
+	t.Helper()
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("reading download: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes that differ from the %d served", len(got), len(content))
+	}
+	if _, err := os.Stat(dest + PartialSuffix); err == nil {
+		t.Errorf("%s left behind", dest+PartialSuffix)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	s := newServer(t, serveContent)
+	dest, err := fetch(t, s, digest(content), nil)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestFetchResumesPartial(t *testing.T) {
+	s := newServer(t, serveContent)
+	dest, err := fetch(t, s, digest(content), func(dest string) {
+		if err := os.WriteFile(dest+PartialSuffix, content[:1000], 0644); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := s.ranges.Load(); n != 1 {
+		t.Errorf("made %d range requests, want 1", n)
+	}
+}
+
+func TestFetchCompletePartialIsNotRequested(t *testing.T) {
+	s := newServer(t, serveContent)
+	dest, err := fetch(t, s, digest(content), func(dest string) {
+		if err := os.WriteFile(dest+PartialSuffix, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := s.requests.Load(); n != 0 {
+		t.Errorf("made %d requests for a complete partial download, want 0", n)
+	}
+}
+
+func TestFetchRestartsWhenRangeIgnored(t *testing.T) {
+	s := newServer(t, func(_ int32, w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	})
+	dest, err := fetch(t, s, digest(content), func(dest string) {
+		if err := os.WriteFile(dest+PartialSuffix, []byte("garbage"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+}
+
+func TestFetchRestartsAfterRangeNotSatisfiable(t *testing.T) {
+	s := newServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Write(content)
+	})
+	dest, err := fetch(t, s, digest(content), func(dest string) {
+		if err := os.WriteFile(dest+PartialSuffix, content[:1000], 0644); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := s.requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want a range request and a full one", n)
+	}
+}
+
+func TestFetchRetriesTransientFailures(t *testing.T) {
+	s := newServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(n, w, r)
+	})
+	dest, err := fetch(t, s, digest(content), nil)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := s.requests.Load(); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestFetchDoesNotRetryPermanentFailures(t *testing.T) {
+	s := newServer(t, func(_ int32, w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	dest, err := fetch(t, s, digest(content), nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Fetch error = %v, want a 404 failure", err)
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		t.Errorf("%s exists after a failed download", dest)
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	s := newServer(t, serveContent)
+	want := digest([]byte("something else"))
+	dest, err := fetch(t, s, want, nil)
+
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("Fetch error = %v, want a ChecksumError", err)
+	}
+	if checksumErr.Expected != want || checksumErr.Actual != digest(content) {
+		t.Errorf("ChecksumError = %+v", checksumErr)
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want no retries after a checksum mismatch", n)
+	}
+	for _, path := range []string{dest, dest + PartialSuffix} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s exists after a checksum mismatch", path)
+		}
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	s := newServer(t, func(_ int32, w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	dest := filepath.Join(t.TempDir(), "go.tar.gz")
+	d := New()
+	d.Timeout = 50 * time.Millisecond
+	err := d.Fetch(context.Background(), Request{URL: s.URL, Filename: "go.tar.gz", Sha256: digest(content)}, dest)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Fetch error = %v, want a timeout", err)
+	}
+}
+
+func TestFetchAnyMovesOnWithoutBackoff(t *testing.T) {
+	// A port nothing listens on refuses connections right away.
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	failing := newServer(t, func(_ int32, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	good := newServer(t, serveContent)
+
+	dest := filepath.Join(t.TempDir(), "go.tar.gz")
+	d := New()
+	d.Backoff = time.Hour
+	urls := []string{dead.URL + "/go.tar.gz", failing.URL + "/go.tar.gz", good.URL + "/go.tar.gz"}
+	if err := d.FetchAny(context.Background(), urls, Request{Filename: "go.tar.gz", Sha256: digest(content)}, dest); err != nil {
+		t.Fatalf("FetchAny: %v", err)
+	}
+	checkDownloaded(t, dest)
+	if n := failing.requests.Load(); n != 1 {
+		t.Errorf("failing mirror got %d requests, want 1", n)
+	}
+}
+
+func TestFetchAnyRetriesAfterEveryMirrorFailed(t *testing.T) {
+	var order []string
+	var mu sync.Mutex
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+	first := newServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		record("first")
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(n, w, r)
+	})
+	missing := newServer(t, func(_ int32, w http.ResponseWriter, r *http.Request) {
+		record("missing")
+		http.NotFound(w, r)
+	})
+
+	dest := filepath.Join(t.TempDir(), "go.tar.gz")
+	d := New()
+	d.Backoff = time.Millisecond
+	urls := []string{first.URL + "/go.tar.gz", missing.URL + "/go.tar.gz"}
+	if err := d.FetchAny(context.Background(), urls, Request{Filename: "go.tar.gz", Sha256: digest(content)}, dest); err != nil {
+		t.Fatalf("FetchAny: %v", err)
+	}
+	checkDownloaded(t, dest)
+	// The 404 is permanent, so only the first mirror is retried.
+	if want := []string{"first", "missing", "first"}; !slices.Equal(order, want) {
+		t.Errorf("requests went to %v, want %v", order, want)
+	}
+}
+
+func TestFetchAnyTimeoutCoversAllMirrors(t *testing.T) {
+	hang := func(_ int32, w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}
+	a, b := newServer(t, hang), newServer(t, hang)
+
+	d := New()
+	d.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	err := d.FetchAny(context.Background(), []string{a.URL, b.URL}, Request{Filename: "go.tar.gz", Sha256: digest(content)}, filepath.Join(t.TempDir(), "go.tar.gz"))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("FetchAny error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("FetchAny took %s, want the timeout to cover all mirrors", elapsed)
+	}
+	if n := b.requests.Load(); n != 0 {
+		t.Errorf("second mirror got %d requests after the deadline", n)
+	}
+}
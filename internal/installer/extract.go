@@ -0,0 +1,203 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// extractTarGz unpacks a Go release archive into targetDir, stripping the
+// archive's top-level "go/" directory.
+//
+// Directories, regular files, symlinks and hard links are recreated with
+// their recorded permissions and modification times. Entries that would
+// resolve outside targetDir are rejected, and so are entries below a symlink
+// an earlier entry created, since writing through it could escape targetDir
+// however harmless the link looks on its own.
+func extractTarGz(file, targetDir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	type dirTime struct {
+		path  string
+		mtime time.Time
+	}
+	var dirs []dirTime
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name, err := entryName(hdr.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(targetDir, name)
+		mode := hdr.FileInfo().Mode().Perm()
+
+		// A directory entry may name an existing symlink, which MkdirAll and
+		// Chmod would follow. Other entries replace whatever is at path.
+		parent := filepath.Dir(name)
+		if hdr.Typeflag == tar.TypeDir {
+			parent = name
+		}
+		if err := checkNoSymlinks(targetDir, parent); err != nil {
+			return fmt.Errorf("illegal path %s: %w", hdr.Name, err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			if err := os.Chmod(path, mode|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTime{path, hdr.ModTime})
+			continue
+
+		case tar.TypeReg:
+			if err := writeFile(path, tr, mode); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) || !within(targetDir, filepath.Join(filepath.Dir(path), hdr.Linkname)) {
+				return fmt.Errorf("illegal symlink %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := replace(path, func() error { return os.Symlink(hdr.Linkname, path) }); err != nil {
+				return err
+			}
+			continue
+
+		case tar.TypeLink:
+			linkName, err := entryName(hdr.Linkname)
+			if err != nil || linkName == "" {
+				return fmt.Errorf("illegal hard link %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := checkNoSymlinks(targetDir, linkName); err != nil {
+				return fmt.Errorf("illegal hard link %s -> %s: %w", hdr.Name, hdr.Linkname, err)
+			}
+			target := filepath.Join(targetDir, linkName)
+			if err := replace(path, func() error { return os.Link(target, path) }); err != nil {
+				return err
+			}
+			continue
+
+		case tar.TypeXGlobalHeader:
+			continue
+
+		default:
+			return fmt.Errorf("unsupported entry %s of type %q", hdr.Name, hdr.Typeflag)
+		}
+
+		if err := os.Chtimes(path, hdr.AccessTime, hdr.ModTime); err != nil {
+			return err
+		}
+	}
+
+	// Directory mtimes change whenever an entry is created inside them, so
+	// they are restored once everything has been written, deepest first.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Chtimes(dirs[i].path, dirs[i].mtime, dirs[i].mtime)
+	}
+	return nil
+}
+
+// entryName strips the top-level "go/" directory from an archive entry and
+// rejects absolute names and names that escape the extraction directory.
+func entryName(name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal absolute path in archive: %s", name)
+	}
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+	if clean == "go" || clean == "." {
+		return "", nil
+	}
+	return strings.TrimPrefix(clean, "go"+string(filepath.Separator)), nil
+}
+
+// checkNoSymlinks reports an error if any existing component of name below
+// dir is a symlink. Components that do not exist yet end the check, since
+// nothing below them can exist either.
+func checkNoSymlinks(dir, name string) error {
+	path := dir
+	for _, part := range strings.Split(name, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", path)
+		}
+	}
+	return nil
+}
+
+// within reports whether path lies inside dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	_ = os.Remove(path)
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// The umask may have masked bits off at creation time.
+	return os.Chmod(path, mode)
+}
+
+// replace creates a link at path with create, removing whatever was there.
+func replace(path string, create func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	_ = os.Remove(path)
+	return create()
+}
@@ -0,0 +1,197 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tarEntry describes one entry of a test archive.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+	mode     int64 // defaults to 0755, or 0644 for regular files
+	modTime  time.Time
+}
+
+func dir(name string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeDir}
+}
+
+func file(name, body string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeReg, body: body}
+}
+
+func symlink(name, linkname string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeSymlink, linkname: linkname}
+}
+
+func hardlink(name, linkname string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeLink, linkname: linkname}
+}
+
+// writeArchive writes entries as a .tar.gz below t.TempDir and returns its
+// path.
+func writeArchive(t *testing.T, entries ...tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "go.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: e.mode, Size: int64(len(e.body)), ModTime: e.modTime}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0755
+			if e.typeflag == tar.TypeReg {
+				hdr.Mode = 0644
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	archive := writeArchive(t,
+		dir("go/"),
+		dir("go/bin/"),
+		file("go/bin/go", "binary"),
+		file("go/VERSION", "go1.22.3"),
+		symlink("go/bin/gofmt", "go"),
+		hardlink("go/bin/go2", "go/bin/go"),
+	)
+	target := t.TempDir()
+	if err := extractTarGz(archive, target); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"bin/go":    "binary",
+		"VERSION":   "go1.22.3",
+		"bin/gofmt": "binary",
+		"bin/go2":   "binary",
+	} {
+		got, err := os.ReadFile(filepath.Join(target, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(target, "bin", "gofmt")); err != nil || link != "go" {
+		t.Errorf("bin/gofmt links to %q (%v), want go", link, err)
+	}
+}
+
+func TestExtractTarGzKeepsModesAndTimes(t *testing.T) {
+	mtime := time.Date(2024, 6, 4, 12, 0, 0, 0, time.UTC)
+	archive := writeArchive(t,
+		tarEntry{name: "go/bin/", typeflag: tar.TypeDir, mode: 0750, modTime: mtime},
+		tarEntry{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755, modTime: mtime},
+		tarEntry{name: "go/pkg/tool/cover", typeflag: tar.TypeReg, body: "tool", mode: 0700, modTime: mtime},
+		tarEntry{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.3", mode: 0444, modTime: mtime},
+	)
+	target := t.TempDir()
+	if err := extractTarGz(archive, target); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+
+	for name, want := range map[string]os.FileMode{
+		"bin":            0750,
+		"bin/go":         0755,
+		"pkg/tool/cover": 0700,
+		"VERSION":        0444,
+	} {
+		info, err := os.Stat(filepath.Join(target, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s has mode %v, want %v", name, got, want)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("%s has mtime %v, want %v", name, info.ModTime(), mtime)
+		}
+	}
+}
+
+func TestExtractTarGzRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{"absolute path", []tarEntry{file("/tmp/evil", "x")}},
+		{"parent path", []tarEntry{file("go/../../evil", "x")}},
+		{"absolute symlink", []tarEntry{symlink("go/link", "/etc")}},
+		{"escaping symlink", []tarEntry{symlink("go/link", "../..")}},
+		{"escaping hard link", []tarEntry{hardlink("go/link", "../evil")}},
+		{
+			// Each link looks harmless on its own, but go/x resolves to
+			// the parent of the extraction directory.
+			"file through symlink chain",
+			[]tarEntry{symlink("go/y", "."), symlink("go/x", "y/.."), file("go/x/evil", "x")},
+		},
+		{
+			"directory through symlink chain",
+			[]tarEntry{symlink("go/y", "."), symlink("go/x", "y/.."), dir("go/x/evil/")},
+		},
+		{
+			"directory entry naming a symlink",
+			[]tarEntry{symlink("go/y", "."), symlink("go/x", "y/.."), dir("go/x/")},
+		},
+		{
+			"hard link through symlink",
+			[]tarEntry{symlink("go/y", "."), symlink("go/x", "y/.."), hardlink("go/evil", "go/x/secret")},
+		},
+		{
+			"symlink through symlink",
+			[]tarEntry{symlink("go/y", "."), symlink("go/x", "y/.."), symlink("go/x/evil", "y")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			target := filepath.Join(parent, "staging")
+			if err := os.Mkdir(target, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(parent, "secret"), []byte("secret"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractTarGz(writeArchive(t, tt.entries...), target)
+			if err == nil {
+				t.Fatal("extractTarGz succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), "illegal") {
+				t.Errorf("error = %v, want an illegal entry error", err)
+			}
+			if _, err := os.Lstat(filepath.Join(parent, "evil")); err == nil {
+				t.Errorf("extractTarGz created %s outside the target directory", filepath.Join(parent, "evil"))
+			}
+		})
+	}
+}
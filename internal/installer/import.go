@@ -0,0 +1,36 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+)
+
+// Import installs version from an unpacked GOROOT at src, such as a
+// toolchain the go command downloaded into its module cache. The tree is
+// copied through a staging directory and validated like a download, and src
+// is left untouched. Importing a version that is already present is a no-op.
+func (i *Installer) Import(version, src string) error {
+	i.CleanStaging()
+	if i.IsInstalled(version) {
+		return nil
+	}
+	if _, err := os.Stat(goBinary(src)); err != nil {
+		return fmt.Errorf("%s is not a Go installation: bin/go missing", src)
+	}
+
+	staging, err := i.newStagingDir(version)
+	if err != nil {
+		return err
+	}
+	defer removeStagingDir(staging)
+
+	// The module cache keeps its files read-only; make the copies writable
+	// so that they can be removed again.
+	if err := CopyTree(src, staging, 0200); err != nil {
+		return fmt.Errorf("copy failed: %w", err)
+	}
+	if err := validate(staging, version); err != nil {
+		return err
+	}
+	return i.commit(staging, version)
+}
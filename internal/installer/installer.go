@@ -0,0 +1,192 @@
+// Package installer downloads, verifies and unpacks Go release archives into
+// the gover versions directory.
+//
+// Every version is installed in the same canonical layout: the contents of
+// the archive's top-level "go" directory become ~/.gover/versions/<version>,
+// so that directory can be used as GOROOT directly.
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mullerhx/gover/internal/cache"
+	"github.com/mullerhx/gover/internal/download"
+)
+
+// DefaultBaseURL is where release archives are downloaded from.
+const DefaultBaseURL = "https://go.dev/dl/"
+
+// Archive identifies a release archive and the checksum it must match.
+type Archive struct {
+	Version  string
+	Filename string
+	Sha256   string
+	Size     int64
+}
+
+// Installer installs Go versions below Versions.
+type Installer struct {
+	// Versions is the directory holding one GOROOT per installed version.
+	Versions string
+	// Current is the path of the symlink to the active version.
+	Current string
+	// Mirrors are base URLs that the archive filename is appended to. They
+	// are tried in order until one of them serves the archive.
+	Mirrors []string
+	// Progress receives download progress when non-nil.
+	Progress io.Writer
+	// Cache keeps downloaded archives for later installs.
+	Cache *cache.Cache
+	// Downloader fetches archives missing from Cache.
+	Downloader *download.Downloader
+	// Offline makes installs fail instead of downloading archives that are
+	// missing from Cache.
+	Offline bool
+}
+
+// New returns an Installer that keeps everything below root, the way
+// ~/.gover is laid out, and downloads from DefaultBaseURL.
+func New(root string) *Installer {
+	return &Installer{
+		Versions:   filepath.Join(root, "versions"),
+		Current:    filepath.Join(root, "current"),
+		Mirrors:    []string{DefaultBaseURL},
+		Cache:      cache.New(filepath.Join(root, "cache")),
+		Downloader: download.New(),
+	}
+}
+
+// VersionsDir returns the directory holding all installed versions.
+func (i *Installer) VersionsDir() string {
+	return i.Versions
+}
+
+// Dir returns the GOROOT of version.
+func (i *Installer) Dir(version string) string {
+	return filepath.Join(i.VersionsDir(), version)
+}
+
+// CurrentLink returns the path of the symlink to the active version.
+func (i *Installer) CurrentLink() string {
+	return i.Current
+}
+
+// IsInstalled reports whether a complete install of version exists,
+// migrating an install in the legacy layout first. A directory without a go
+// binary is left over from an interrupted install and does not count.
+func (i *Installer) IsInstalled(version string) bool {
+	if err := i.Migrate(version); err != nil {
+		return false
+	}
+	_, err := os.Stat(goBinary(i.Dir(version)))
+	return err == nil
+}
+
+// Migrate moves an install of version that still uses the legacy
+// versions/<version>/go layout into the canonical one, repointing the
+// current symlink if it referred to the old location.
+func (i *Installer) Migrate(version string) error {
+	dir := i.Dir(version)
+	legacy := filepath.Join(dir, "go")
+	tmp := dir + ".migrate"
+	if _, err := os.Stat(filepath.Join(dir, "bin")); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "bin")); err == nil {
+		if err := os.Rename(legacy, tmp); err != nil {
+			return fmt.Errorf("migrate %s: %w", version, err)
+		}
+	} else if _, err := os.Stat(filepath.Join(tmp, "bin")); err != nil {
+		// Neither a legacy install nor an interrupted migration.
+		return nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("migrate %s: %w", version, err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("migrate %s: %w", version, err)
+	}
+
+	link := i.CurrentLink()
+	if target, err := os.Readlink(link); err == nil && filepath.Clean(target) == legacy {
+		_ = os.Remove(link)
+		if err := os.Symlink(dir, link); err != nil {
+			return fmt.Errorf("migrate %s: failed to repoint current: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// Install downloads archive, verifies its checksum and extracts it into a
+// staging directory. Only once the staged toolchain reports the expected
+// version is it renamed into the canonical location, so an interrupted
+// install never leaves a partial version behind. Installing a version that
+// is already present is a no-op.
+func (i *Installer) Install(archive Archive) error {
+	i.CleanStaging()
+	if i.IsInstalled(archive.Version) {
+		return nil
+	}
+
+	file, err := i.fetch(archive)
+	if err != nil {
+		return err
+	}
+
+	staging, err := i.newStagingDir(archive.Version)
+	if err != nil {
+		return err
+	}
+	defer removeStagingDir(staging)
+
+	if i.Progress != nil {
+		fmt.Fprintln(i.Progress, "Extracting:", filepath.Base(file))
+	}
+	if err := extractTarGz(file, staging); err != nil {
+		return fmt.Errorf("extract failed: %w", err)
+	}
+	if err := validate(staging, archive.Version); err != nil {
+		return err
+	}
+	return i.commit(staging, archive.Version)
+}
+
+// fetch returns the path of archive in the cache, downloading it first when
+// it is not there yet.
+func (i *Installer) fetch(archive Archive) (string, error) {
+	if path, ok := i.Cache.Lookup(archive.Filename, archive.Sha256); ok {
+		if i.Progress != nil {
+			fmt.Fprintln(i.Progress, "Using cached archive:", path)
+		}
+		return path, nil
+	}
+
+	if i.Offline {
+		return "", fmt.Errorf("%s not in local cache", archive.Version)
+	}
+	if len(i.Mirrors) == 0 {
+		return "", fmt.Errorf("no download mirror configured")
+	}
+
+	i.Downloader.Progress = i.Progress
+	urls := make([]string, len(i.Mirrors))
+	for n, mirror := range i.Mirrors {
+		urls[n] = strings.TrimSuffix(mirror, "/") + "/" + archive.Filename
+	}
+	path := i.Cache.Path(archive.Filename, archive.Sha256)
+	err := i.Downloader.FetchAny(context.Background(), urls, download.Request{
+		Filename: archive.Filename,
+		Sha256:   archive.Sha256,
+		Size:     archive.Size,
+	}, path)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,122 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// stagingPrefix marks directories below VersionsDir that hold an install in
+// progress. They are named .staging-<version>-<pid>.
+const stagingPrefix = ".staging-"
+
+// activeStaging holds the staging directories of installs running in this
+// process, which CleanStaging must leave alone.
+var activeStaging sync.Map
+
+func (i *Installer) newStagingDir(version string) (string, error) {
+	if err := os.MkdirAll(i.VersionsDir(), 0755); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(i.VersionsDir(), fmt.Sprintf("%s%s-%d", stagingPrefix, version, os.Getpid()))
+	// Register dir before it exists so that a CleanStaging running
+	// alongside never sees it unregistered.
+	activeStaging.Store(dir, true)
+	if err := os.RemoveAll(dir); err != nil {
+		activeStaging.Delete(dir)
+		return "", err
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		activeStaging.Delete(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// removeStagingDir removes a directory created by newStagingDir once the
+// install using it has finished.
+func removeStagingDir(dir string) {
+	_ = os.RemoveAll(dir)
+	activeStaging.Delete(dir)
+}
+
+// CleanStaging removes staging directories of installs whose process is no
+// longer running. Installs still running in this process are left
+// alone.
+func (i *Installer) CleanStaging() {
+	entries, err := os.ReadDir(i.VersionsDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, stagingPrefix) {
+			continue
+		}
+		idx := strings.LastIndex(name, "-")
+		pid, err := strconv.Atoi(name[idx+1:])
+		if err == nil && pid != os.Getpid() && processAlive(pid) {
+			continue
+		}
+		dir := filepath.Join(i.VersionsDir(), name)
+		if _, ok := activeStaging.Load(dir); ok {
+			continue
+		}
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// validate checks that dir holds a working toolchain for version.
+func validate(dir, version string) error {
+	bin := goBinary(dir)
+	if _, err := os.Stat(bin); err != nil {
+		return fmt.Errorf("invalid archive for %s: bin/go missing", version)
+	}
+
+	cmd := exec.Command(bin, "version")
+	cmd.Env = append(os.Environ(), "GOROOT="+dir, "GOTOOLCHAIN=local")
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("invalid install of %s: go version failed: %w", version, err)
+	}
+	if !bytes.Contains(out, []byte(" "+version+" ")) {
+		return fmt.Errorf("invalid install of %s: go version reported %q", version, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// commit moves a validated staging directory into place, replacing any
+// incomplete directory left by an earlier interrupted install.
+func (i *Installer) commit(staging, version string) error {
+	dir := i.Dir(version)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", version, err)
+	}
+	return nil
+}
+
+func goBinary(dir string) string {
+	name := "go"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(dir, "bin", name)
+}
@@ -0,0 +1,36 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanStagingKeepsActiveInstalls(t *testing.T) {
+	i := New(t.TempDir())
+	active, err := i.newStagingDir("go1.22.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeStagingDir(active)
+
+	// Left behind by an earlier process that had the same pid.
+	stale := filepath.Join(i.VersionsDir(), fmt.Sprintf("%sgo1.21.0-%d", stagingPrefix, os.Getpid()))
+	if err := os.Mkdir(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	i.CleanStaging()
+	if _, err := os.Stat(active); err != nil {
+		t.Errorf("CleanStaging removed the staging directory of a running install: %v", err)
+	}
+	if _, err := os.Stat(stale); err == nil {
+		t.Errorf("CleanStaging kept stale staging directory %s", stale)
+	}
+
+	removeStagingDir(active)
+	if _, ok := activeStaging.Load(active); ok {
+		t.Errorf("removeStagingDir left %s registered", active)
+	}
+}
@@ -0,0 +1,248 @@
+// Package project finds the Go version a directory asks for.
+//
+// Explicit version files win over the module they belong to: the nearest
+// .go-version or .tool-versions (with a "golang" entry) found by walking up
+// from the starting directory is used, and only when there is none does the
+// module decide. The walk goes up to the filesystem root, so a version file
+// at the root of a repository covers every module below it. The one
+// exception is the home directory, where asdf keeps its global default: a
+// version file there only applies outside modules. Within one directory
+// .go-version beats .tool-versions.
+//
+// For modules the go command's own rules apply: a go.work file (found the
+// same way, or named by GOWORK) takes precedence over the nearest go.mod,
+// and within either file the toolchain directive beats the go directive.
+package project
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// File names recognised by Detect.
+const (
+	GoVersionFile    = ".go-version"
+	ToolVersionsFile = ".tool-versions"
+	GoModFile        = "go.mod"
+	GoWorkFile       = "go.work"
+)
+
+// Directives of go.mod and go.work that request a version.
+const (
+	GoDirective        = "go"
+	ToolchainDirective = "toolchain"
+)
+
+// ErrNotFound is returned when no directory up to the root specifies a
+// version.
+var ErrNotFound = errors.New("no .go-version, .tool-versions, go.work or go.mod found")
+
+// Request is a version requested by a project file.
+type Request struct {
+	// Version is the version or constraint as written, e.g. "1.22.3".
+	Version string
+	// File is the path of the file the version was read from.
+	File string
+	// Directive is the go.mod or go.work directive the version came from,
+	// or "" for version files.
+	Directive string
+	// Exact is set for version files and toolchain directives, which pin
+	// what to use; the go directive only states a minimum.
+	Exact bool
+}
+
+// Detect returns the version requested for dir.
+func Detect(dir string) (Request, error) {
+	if req, ok, err := findVersionFile(dir); ok || err != nil {
+		return req, err
+	}
+	return findModule(dir)
+}
+
+// findVersionFile returns the nearest version file from dir up to the
+// filesystem root. Those in the home directory are skipped once the walk
+// has passed a module or workspace.
+func findVersionFile(dir string) (Request, bool, error) {
+	home, err := os.UserHomeDir()
+	if err == nil {
+		home = filepath.Clean(home)
+	}
+	inModule := false
+	for d := dir; ; {
+		if d == home && inModule {
+			return Request{}, false, nil
+		}
+		for _, name := range []string{GoVersionFile, ToolVersionsFile} {
+			path := filepath.Join(d, name)
+			v, err := readVersionFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return Request{}, false, err
+			}
+			if v != "" {
+				return Request{Version: v, File: path, Exact: true}, true, nil
+			}
+		}
+		inModule = inModule || isModuleRoot(d)
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return Request{}, false, nil
+		}
+		d = parent
+	}
+}
+
+// isModuleRoot reports whether dir holds a go.mod or go.work file.
+func isModuleRoot(dir string) bool {
+	for _, name := range []string{GoModFile, GoWorkFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// findModule returns the version requested by the workspace or module
+// dir belongs to.
+func findModule(dir string) (Request, error) {
+	if path, err := findWorkFile(dir); err != nil {
+		return Request{}, err
+	} else if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Request{}, err
+		}
+		work, err := modfile.ParseWork(path, data, nil)
+		if err != nil {
+			return Request{}, err
+		}
+		if req, ok := moduleRequest(path, work.Go, work.Toolchain); ok {
+			return req, nil
+		}
+		// A go.work without a version leaves it to the module.
+	}
+
+	path, ok := findUp(dir, GoModFile)
+	if !ok {
+		return Request{}, ErrNotFound
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Request{}, err
+	}
+	mod, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return Request{}, err
+	}
+	if req, ok := moduleRequest(path, mod.Go, mod.Toolchain); ok {
+		return req, nil
+	}
+	return Request{}, fmt.Errorf("%s has no go directive", path)
+}
+
+// findWorkFile returns the go.work file the go command would use in dir, or
+// "" when there is none.
+func findWorkFile(dir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return "", nil
+	case "":
+		path, _ := findUp(dir, GoWorkFile)
+		return path, nil
+	default:
+		if !filepath.IsAbs(gowork) {
+			return "", fmt.Errorf("GOWORK must be an absolute path: %s", gowork)
+		}
+		return gowork, nil
+	}
+}
+
+// moduleRequest prefers the toolchain directive, the exact toolchain the
+// file asks for, over the go directive.
+func moduleRequest(path string, goLine *modfile.Go, toolchain *modfile.Toolchain) (Request, bool) {
+	if toolchain != nil {
+		if v := toolchainVersion(toolchain.Name); v != "" {
+			return Request{Version: v, File: path, Directive: ToolchainDirective, Exact: true}, true
+		}
+	}
+	if goLine != nil {
+		return Request{Version: goLine.Version, File: path, Directive: GoDirective}, true
+	}
+	return Request{}, false
+}
+
+// toolchainVersion returns the Go version of a toolchain name such as
+// go1.22.3 or go1.22.3-custom, or "" for "default".
+func toolchainVersion(name string) string {
+	v, ok := strings.CutPrefix(name, "go")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// findUp returns the path of the nearest file called name in dir or one of
+// its parents.
+func findUp(dir, name string) (string, bool) {
+	for d := dir; ; {
+		path := filepath.Join(d, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", false
+		}
+		d = parent
+	}
+}
+
+// readVersionFile returns the Go version in a .go-version or
+// .tool-versions file, or "" when a .tool-versions file has no golang entry.
+func readVersionFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	toolVersions := filepath.Base(path) == ToolVersionsFile
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !toolVersions {
+			return fields[0], nil
+		}
+		// asdf lists fallbacks after the first version; use the first.
+		if fields[0] == "golang" && len(fields) > 1 {
+			return fields[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// WriteVersionFile writes version to the .go-version file in dir and
+// returns its path.
+func WriteVersionFile(dir, version string) (string, error) {
+	path := filepath.Join(dir, GoVersionFile)
+	return path, os.WriteFile(path, []byte(strings.TrimPrefix(version, "go")+"\n"), 0644)
+}
@@ -0,0 +1,116 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		home      string // relative to the test root
+		dir       string
+		version   string
+		directive string
+	}{
+		{
+			name:    "version file",
+			files:   map[string]string{"proj/.go-version": "1.22.3\n"},
+			dir:     "proj",
+			version: "1.22.3",
+		},
+		{
+			name:    "go-version beats tool-versions",
+			files:   map[string]string{"proj/.go-version": "1.22.3\n", "proj/.tool-versions": "golang 1.21.0\n"},
+			dir:     "proj",
+			version: "1.22.3",
+		},
+		{
+			name:      "toolchain beats go directive",
+			files:     map[string]string{"proj/go.mod": "module x\n\ngo 1.21\n\ntoolchain go1.22.3\n"},
+			dir:       "proj/sub",
+			version:   "1.22.3",
+			directive: ToolchainDirective,
+		},
+		{
+			name:    "version file in module beats go.mod",
+			files:   map[string]string{"proj/go.mod": "module x\n\ngo 1.21\n", "proj/sub/.go-version": "1.20.1\n"},
+			dir:     "proj/sub",
+			version: "1.20.1",
+		},
+		{
+			name:    "repository version file covers nested modules",
+			files:   map[string]string{"repo/.go-version": "1.21.0\n", "repo/svc/go.mod": "module x\n\ngo 1.22\n"},
+			dir:     "repo/svc",
+			version: "1.21.0",
+		},
+		{
+			name: "home version file is ignored in modules",
+			files: map[string]string{
+				"home/.tool-versions": "golang 1.20.1\n",
+				"home/proj/go.mod":    "module x\n\ngo 1.21\n\ntoolchain go1.22.3\n",
+			},
+			home:      "home",
+			dir:       "home/proj/sub",
+			version:   "1.22.3",
+			directive: ToolchainDirective,
+		},
+		{
+			name: "home version file applies outside modules",
+			files: map[string]string{
+				"home/.tool-versions": "golang 1.20.1\n",
+				"home/plain/README":   "",
+			},
+			home:    "home",
+			dir:     "home/plain",
+			version: "1.20.1",
+		},
+		{
+			name: "go.work beats go.mod",
+			files: map[string]string{
+				"ws/go.work":     "go 1.22\n\nuse ./mod\n",
+				"ws/mod/go.mod":  "module x\n\ngo 1.21\n",
+				"ws/mod/main.go": "package x\n",
+			},
+			dir:       "ws/mod",
+			version:   "1.22",
+			directive: GoDirective,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			home := tt.home
+			if home == "" {
+				home = "no-home"
+			}
+			t.Setenv("HOME", filepath.Join(root, home))
+			for name, content := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dir := filepath.Join(root, tt.dir)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if req.Version != tt.version || req.Directive != tt.directive {
+				t.Errorf("Detect = %q (directive %q), want %q (directive %q)", req.Version, req.Directive, tt.version, tt.directive)
+			}
+		})
+	}
+}
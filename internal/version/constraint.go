@@ -0,0 +1,252 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint selects a version from a set of available releases. It is
+// parsed from expressions such as:
+//
+//	1.22, 1.22.x, go1.22   newest release of the 1.22 line
+//	1.22.3                 exactly go1.22.3
+//	~1.21, ~1.21.3         newest 1.21 release, at least 1.21.3
+//	^1.21                  newest release from 1.21 on within major 1
+//	>=1.21 <1.23           all comparisons must hold; "||" separates alternatives
+//	latest, stable         newest stable release
+//	oldstable              newest release of the previous minor line
+//
+// Pre-releases are only selected when the expression names one.
+type Constraint struct {
+	raw   string
+	alias string
+	// any is a disjunction of conjunctions.
+	any [][]term
+	pre bool
+}
+
+type term struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint parses a constraint expression.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: s}
+	expr := strings.TrimSpace(s)
+	switch strings.ToLower(expr) {
+	case "latest", "stable":
+		c.alias = "stable"
+		return c, nil
+	case "oldstable":
+		c.alias = "oldstable"
+		return c, nil
+	case "":
+		return c, fmt.Errorf("empty version constraint")
+	}
+
+	for _, alt := range strings.Split(expr, "||") {
+		fields := strings.Fields(strings.ReplaceAll(alt, ",", " "))
+		if len(fields) == 0 {
+			return c, fmt.Errorf("invalid version constraint %q", s)
+		}
+		var all []term
+		for i := 0; i < len(fields); i++ {
+			f := fields[i]
+			// Allow a space between operator and version: ">= 1.21".
+			if isOperator(f) && i+1 < len(fields) {
+				i++
+				f += fields[i]
+			}
+			terms, err := parseTerm(f)
+			if err != nil {
+				return c, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			for _, t := range terms {
+				if t.v.Pre != "" {
+					c.pre = true
+				}
+			}
+			all = append(all, terms...)
+		}
+		c.any = append(c.any, all)
+	}
+	return c, nil
+}
+
+// String returns the expression c was parsed from.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Exact returns the single version c names when it is an exact release such
+// as 1.22.3 or 1.23rc1, so callers can skip consulting an index.
+func (c Constraint) Exact() (Version, bool) {
+	if c.alias != "" || len(c.any) != 1 || len(c.any[0]) != 1 || c.any[0][0].op != "=" {
+		return Version{}, false
+	}
+	return c.any[0][0].v, true
+}
+
+// NeedsIndex reports whether c is defined relative to the newest Go release,
+// as stable and oldstable are, and so can only be resolved against the full
+// release index rather than a subset such as the installed versions.
+func (c Constraint) NeedsIndex() bool {
+	return c.alias != ""
+}
+
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+func isOperator(s string) bool {
+	for _, op := range operators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTerm(s string) ([]term, error) {
+	op := ""
+	for _, o := range operators {
+		if strings.HasPrefix(s, o) {
+			op, s = o, s[len(o):]
+			break
+		}
+	}
+
+	wildcard := strings.HasSuffix(s, ".x") || strings.HasSuffix(s, ".*")
+	if wildcard {
+		s = s[:len(s)-2]
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if wildcard && (v.hasPatch || v.Pre != "" || (op != "" && op != "=")) {
+		return nil, fmt.Errorf("wildcard not allowed in %q", s)
+	}
+
+	switch op {
+	case "", "=":
+		if !v.hasPatch && v.Pre == "" {
+			// A release line: anything from its first release up to the next
+			// minor version.
+			return lineRange(v), nil
+		}
+		return []term{{"=", v}}, nil
+	case "~":
+		terms := lineRange(v)
+		if v.hasPatch {
+			terms[0] = term{">=", v}
+		}
+		return terms, nil
+	case "^":
+		return []term{{">=", v}, {"<", Version{Major: v.Major + 1, hasMinor: true}}}, nil
+	case "<=":
+		if !v.hasPatch && v.Pre == "" {
+			return []term{{"<", nextMinor(v)}}, nil
+		}
+	case ">":
+		if !v.hasPatch && v.Pre == "" {
+			return []term{{">=", nextMinor(v)}}, nil
+		}
+	}
+	return []term{{op, v}}, nil
+}
+
+// lineRange returns the terms matching every release of v's minor line.
+func lineRange(v Version) []term {
+	first := Version{Major: v.Major, Minor: v.Minor, hasMinor: true}
+	return []term{{">=", first}, {"<", nextMinor(v)}}
+}
+
+// nextMinor returns the language version following v's minor line. Go 1.21
+// and later language versions sort before all of their releases.
+func nextMinor(v Version) Version {
+	next := Version{Major: v.Major, Minor: v.Minor + 1, hasMinor: true}
+	if !next.isLanguage() {
+		// Before Go 1.21 the bare minor version was the release itself, so
+		// bound by its first pre-release instead.
+		next.Pre = "alpha"
+	}
+	return next
+}
+
+func (t term) match(v Version) bool {
+	c := v.Compare(t.v)
+	switch t.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// Check reports whether v satisfies c. Aliases such as stable cannot be
+// decided for a single version and never match.
+func (c Constraint) Check(v Version) bool {
+	if c.alias != "" || (v.IsPrerelease() && !c.pre) {
+		return false
+	}
+	for _, all := range c.any {
+		ok := true
+		for _, t := range all {
+			if !t.match(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the newest of available that satisfies c.
+func (c Constraint) Select(available []Version) (Version, bool) {
+	var stable []Version
+	for _, v := range available {
+		if !v.IsPrerelease() {
+			stable = append(stable, v)
+		}
+	}
+
+	switch c.alias {
+	case "stable":
+		return newest(stable, nil)
+	case "oldstable":
+		latest, ok := newest(stable, nil)
+		if !ok {
+			return Version{}, false
+		}
+		return newest(stable, func(v Version) bool {
+			return v.Major == latest.Major && v.Minor < latest.Minor || v.Major < latest.Major
+		})
+	}
+	return newest(available, c.Check)
+}
+
+func newest(versions []Version, keep func(Version) bool) (Version, bool) {
+	var best Version
+	found := false
+	for _, v := range versions {
+		if keep != nil && !keep(v) {
+			continue
+		}
+		if !found || best.Less(v) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
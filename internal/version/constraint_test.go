@@ -0,0 +1,100 @@
+package version
+
+import "testing"
+
+// releases is a slice of the release index used by the constraint tests.
+var releases = []string{
+	"go1.9.2rc2", "go1.9.2",
+	"go1.20rc1", "go1.20", "go1.20.1", "go1.20.14",
+	"go1.21rc2", "go1.21.0", "go1.21.3", "go1.21.13",
+	"go1.22rc1", "go1.22.0", "go1.22.5",
+	"go1.23rc1",
+}
+
+func TestConstraintSelect(t *testing.T) {
+	available := make([]Version, len(releases))
+	for i, r := range releases {
+		available[i] = mustParse(t, r)
+	}
+
+	tests := []struct {
+		expr string
+		want string // "" when nothing matches
+	}{
+		{"1.21", "go1.21.13"},
+		{"1.21.x", "go1.21.13"},
+		{"go1.21", "go1.21.13"},
+		{"1.20", "go1.20.14"},
+		{"1.21.3", "go1.21.3"},
+		{"=1.21.3", "go1.21.3"},
+		{"1.21.4", ""},
+		{"~1.21", "go1.21.13"},
+		{"~1.21.3", "go1.21.13"},
+		{"^1.20", "go1.22.5"},
+		{">=1.20 <1.22", "go1.21.13"},
+		{">= 1.20, < 1.22", "go1.21.13"},
+		{"<=1.21", "go1.21.13"},
+		{">1.21", "go1.22.5"},
+		{"<1.21", "go1.20.14"},
+		{"!=1.22.5 >=1.22", "go1.22.0"},
+		{"1.19 || 1.20", "go1.20.14"},
+		{"latest", "go1.22.5"},
+		{"stable", "go1.22.5"},
+		{"oldstable", "go1.21.13"},
+		// Pre-releases are only selected when named.
+		{"1.23", ""},
+		{"1.23rc1", "go1.23rc1"},
+		{">=1.22rc1", "go1.23rc1"},
+		{"1.9.2rc2", "go1.9.2rc2"},
+		{"1.9", "go1.9.2"},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.expr)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.expr, err)
+			continue
+		}
+		got, ok := c.Select(available)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("%q selected %s, want no match", tt.expr, got)
+		case tt.want != "" && !ok:
+			t.Errorf("%q matched nothing, want %s", tt.expr, tt.want)
+		case ok && got.String() != tt.want:
+			t.Errorf("%q selected %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintExact(t *testing.T) {
+	tests := []struct {
+		expr  string
+		want  string
+		exact bool
+	}{
+		{"1.22.3", "go1.22.3", true},
+		{"go1.23rc1", "go1.23rc1", true},
+		{"go1.9.2rc2", "go1.9.2rc2", true},
+		{"1.22", "", false},
+		{"~1.22.3", "", false},
+		{"stable", "", false},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.expr, err)
+		}
+		v, ok := c.Exact()
+		if ok != tt.exact || (ok && v.String() != tt.want) {
+			t.Errorf("%q.Exact() = %s, %v; want %s, %v", tt.expr, v, ok, tt.want, tt.exact)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, expr := range []string{"", "   ", "1.x.3", "~1.22.x", ">=1.22.x", "1.22rc1.x", "nonsense", ">=", "1.22 ||"} {
+		if _, err := ParseConstraint(expr); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want an error", expr)
+		}
+	}
+}
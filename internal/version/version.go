@@ -0,0 +1,207 @@
+// Package version parses Go release names such as go1.9, go1.20, go1.21.0,
+// go1.22rc2 and go1.9.2rc2 into values that order the way Go releases do.
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed Go release or language version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Pre is "alpha", "beta" or "rc" for pre-releases and empty otherwise.
+	// Pre-releases of a minor version, such as go1.22rc2, have no patch
+	// number; those of a patch release, such as go1.9.2rc2, do.
+	Pre    string
+	PreNum int
+
+	// hasMinor and hasPatch record which components were written out, so
+	// that go1.20 and go1.20.0 keep their spelling and can act as prefixes.
+	hasMinor bool
+	hasPatch bool
+}
+
+var preOrder = map[string]int{"alpha": 1, "beta": 2, "rc": 3}
+
+// Parse parses a Go version with or without the "go" prefix.
+func Parse(s string) (Version, error) {
+	raw := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "go")
+	if s == "" {
+		return Version{}, fmt.Errorf("invalid Go version %q", raw)
+	}
+
+	var v Version
+	for pre := range preOrder {
+		if i := strings.Index(s, pre); i > 0 {
+			n, err := strconv.Atoi(s[i+len(pre):])
+			if err != nil || n < 0 {
+				return Version{}, fmt.Errorf("invalid Go version %q", raw)
+			}
+			v.Pre, v.PreNum = pre, n
+			s = s[:i]
+			break
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid Go version %q", raw)
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (len(p) > 1 && p[0] == '0') {
+			return Version{}, fmt.Errorf("invalid Go version %q", raw)
+		}
+		nums[i] = n
+	}
+	v.Major = nums[0]
+	if len(nums) > 1 {
+		v.Minor, v.hasMinor = nums[1], true
+	}
+	if len(nums) > 2 {
+		v.Patch, v.hasPatch = nums[2], true
+	}
+	return v, nil
+}
+
+// String returns the release name, e.g. "go1.21.0", "go1.22rc1" or
+// "go1.9.2rc2".
+func (v Version) String() string {
+	s := "go" + strconv.Itoa(v.Major)
+	if v.hasMinor {
+		s += "." + strconv.Itoa(v.Minor)
+	}
+	if v.hasPatch {
+		s += "." + strconv.Itoa(v.Patch)
+	}
+	if v.Pre != "" {
+		s += v.Pre + strconv.Itoa(v.PreNum)
+	}
+	return s
+}
+
+// MinorString returns the release line v belongs to, e.g. "go1.21".
+func (v Version) MinorString() string {
+	return fmt.Sprintf("go%d.%d", v.Major, v.Minor)
+}
+
+// IsPrerelease reports whether v is an alpha, beta or release candidate.
+func (v Version) IsPrerelease() bool {
+	return v.Pre != ""
+}
+
+// Release returns the first release of a language version, e.g. go1.21.0
+// for go1.21, and v itself when it already names a release.
+func (v Version) Release() Version {
+	if v.isLanguage() {
+		v.Patch, v.hasPatch = 0, true
+	}
+	return v
+}
+
+// isLanguage reports whether v names a language version rather than a
+// release. From Go 1.21 on, "go1.21" is the language version that precedes
+// go1.21rc1 and go1.21.0; before that, "go1.20" was itself the release.
+func (v Version) isLanguage() bool {
+	return v.Pre == "" && !v.hasPatch && (v.Major > 1 || v.Minor >= 21)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v sorts before, equal to
+// or after w.
+func (v Version) Compare(w Version) int {
+	if c := cmpInt(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := cmpInt(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := cmpInt(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+	if c := cmpInt(v.stage(), w.stage()); c != 0 {
+		return c
+	}
+	return cmpInt(v.PreNum, w.PreNum)
+}
+
+// stage orders the forms a version takes before its patch number matters:
+// language version, alpha, beta, rc, then the release itself.
+func (v Version) stage() int {
+	switch {
+	case v.isLanguage():
+		return 0
+	case v.Pre != "":
+		return preOrder[v.Pre]
+	default:
+		return 4
+	}
+}
+
+// Less reports whether v sorts before w.
+func (v Version) Less(w Version) bool {
+	return v.Compare(w) < 0
+}
+
+// HasPrefix reports whether v lies within the release line or release named
+// by prefix: go1.21 contains go1.21rc1 and every go1.21.x but not go1.210,
+// and go1.21.3 contains only itself.
+func (v Version) HasPrefix(prefix Version) bool {
+	if v.Major != prefix.Major {
+		return false
+	}
+	if !prefix.hasMinor {
+		return true
+	}
+	if v.Minor != prefix.Minor {
+		return false
+	}
+	if prefix.Pre != "" {
+		return v.Patch == prefix.Patch && v.Pre == prefix.Pre && v.PreNum == prefix.PreNum
+	}
+	if !prefix.hasPatch {
+		return true
+	}
+	return v.Pre == "" && v.Patch == prefix.Patch
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Sort sorts versions in ascending order.
+func Sort(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Less(versions[j])
+	})
+}
+
+// SortStrings sorts version names in ascending order. Names that do not
+// parse sort before all valid versions, in string order.
+func SortStrings(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		a, errA := Parse(names[i])
+		b, errB := Parse(names[j])
+		switch {
+		case errA != nil && errB != nil:
+			return names[i] < names[j]
+		case errA != nil:
+			return true
+		case errB != nil:
+			return false
+		}
+		return a.Less(b)
+	})
+}
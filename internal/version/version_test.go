@@ -0,0 +1,122 @@
+package version
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"go1.9", "go1.9"},
+		{"1.20", "go1.20"},
+		{"go1.21.0", "go1.21.0"},
+		{" go1.22.3 ", "go1.22.3"},
+		{"go1.22rc2", "go1.22rc2"},
+		{"go1.21beta1", "go1.21beta1"},
+		{"go1.9.2rc2", "go1.9.2rc2"},
+		{"go1", "go1"},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := v.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "go", "1.2.3.4", "1.x", "1.-2", "1.02", "go1.22rc", "go1.22rcx", "tip"} {
+		if v, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, v)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version sorts before the next.
+	ordered := []string{
+		"go1.9beta1",
+		"go1.9rc1",
+		"go1.9",
+		"go1.9.1",
+		"go1.9.2rc2",
+		"go1.9.2",
+		"go1.20rc1",
+		"go1.20",
+		"go1.20.1",
+		"go1.21",
+		"go1.21rc1",
+		"go1.21rc2",
+		"go1.21.0",
+		"go1.21.10",
+		"go1.22rc1",
+		"go2.0.0",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, b := mustParse(t, ordered[i]), mustParse(t, ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("%s should sort before %s", a, b)
+		}
+		if a.Compare(a) != 0 {
+			t.Errorf("%s should equal itself", a)
+		}
+	}
+
+	shuffled := []string{"go1.21.0", "bogus", "go1.9.2rc2", "go1.20", "go1.21rc1", "go1.9.2"}
+	SortStrings(shuffled)
+	want := []string{"bogus", "go1.9.2rc2", "go1.9.2", "go1.20", "go1.21rc1", "go1.21.0"}
+	if !slices.Equal(shuffled, want) {
+		t.Errorf("SortStrings = %v, want %v", shuffled, want)
+	}
+}
+
+func TestHasPrefix(t *testing.T) {
+	tests := []struct {
+		v, prefix string
+		want      bool
+	}{
+		{"go1.21.3", "go1.21", true},
+		{"go1.21rc1", "go1.21", true},
+		{"go1.21.3", "go1", true},
+		{"go1.210.0", "go1.21", false},
+		{"go1.21.3", "go1.21.3", true},
+		{"go1.21.4", "go1.21.3", false},
+		{"go1.9.2rc2", "go1.9.2", false},
+		{"go1.9.2rc2", "go1.9.2rc2", true},
+		{"go1.9rc2", "go1.9.2rc2", false},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.v).HasPrefix(mustParse(t, tt.prefix)); got != tt.want {
+			t.Errorf("%s.HasPrefix(%s) = %v, want %v", tt.v, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestRelease(t *testing.T) {
+	tests := map[string]string{
+		"go1.21":    "go1.21.0",
+		"go1.22":    "go1.22.0",
+		"go1.20":    "go1.20",
+		"go1.21.3":  "go1.21.3",
+		"go1.22rc1": "go1.22rc1",
+	}
+	for in, want := range tests {
+		if got := mustParse(t, in).Release().String(); got != want {
+			t.Errorf("%s.Release() = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}